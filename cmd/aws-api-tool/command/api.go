@@ -8,76 +8,128 @@ package command
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"sync"
 
 	"github.com/jaypipes/aws-api-tools/pkg/apimodel"
 	"github.com/jaypipes/aws-api-tools/pkg/model"
+	"github.com/spf13/cobra"
 )
 
-const (
-	sdkRepoURL = "https://github.com/aws/aws-sdk-go"
+var (
+	cliAPIVersion string
 )
 
-func ensureSDKRepo() (string, error) {
-	srcPath := filepath.Join(cachePath, "src")
-	if err := os.MkdirAll(srcPath, os.ModePerm); err != nil {
-		return "", err
-	}
-	// clone the aws-sdk-go repository locally so we can query for API
-	// information in the models/apis/ directories
-	trace("cloning aws-sdk-go to local cache %s ...\n", srcPath)
-	clonePath, err := cloneSDKRepo(srcPath)
+// addAPIVersionFlag adds the --api-version flag to a command that operates on
+// a single <api>
+func addAPIVersionFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(
+		&cliAPIVersion, "api-version", "", "API version of the service to use (defaults to the newest API version).",
+	)
+}
+
+// getSDKHelper returns a model.SDKHelper that reads API models from the
+// directory supplied with --models-dir, the archive supplied with
+// --models-archive or, if neither was supplied, from a clone of the
+// aws-sdk-go repository in the local cache
+func getSDKHelper() (*model.SDKHelper, error) {
+	if modelsDir != "" && modelsArchive != "" {
+		return nil, fmt.Errorf("--models-dir and --models-archive are mutually exclusive")
+	}
+	if modelsArchive != "" {
+		trace("reading models from archive %s ...\n", modelsArchive)
+		src, err := model.NewArchiveSource(modelsArchive)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewSDKHelperFromSource(src), nil
+	}
+	if modelsDir != "" {
+		trace("using local models directory %s ...\n", modelsDir)
+		return model.NewSDKHelperFromModelsDir(modelsDir)
+	}
+	sdkPath, err := ensureSDKRepo()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return clonePath, nil
+	return model.NewSDKHelper(sdkPath), nil
 }
 
 type APIFilter struct {
 	anyMatch         []string
 	anyProtocolMatch []string
+	// apiVersion selects a specific API version of the matched services
+	// instead of the newest one
+	apiVersion string
+	// allVersions selects every API version of the matched services instead
+	// of only the newest one
+	allVersions bool
+}
+
+// apiRef identifies one API version of a service
+type apiRef struct {
+	alias   string
+	version string
+}
+
+// getAPIRefs returns the service aliases and API versions matching the
+// supplied filter's alias and version criteria. An empty version refers to
+// the newest API version of the service.
+func getAPIRefs(
+	sdkHelper *model.SDKHelper,
+	filter *APIFilter,
+) ([]apiRef, error) {
+	refs := []apiRef{}
+	aliases, err := sdkHelper.ServiceAliases()
+	if err != nil {
+		return refs, err
+	}
+	for _, alias := range aliases {
+		if filter != nil && len(filter.anyMatch) > 0 {
+			if !inStrings(alias, filter.anyMatch) {
+				continue
+			}
+		}
+		versions := []string{""}
+		if filter != nil && filter.allVersions {
+			versions, err = sdkHelper.APIVersions(alias)
+			if err != nil {
+				return refs, err
+			}
+		} else if filter != nil && filter.apiVersion != "" {
+			versions = []string{filter.apiVersion}
+		}
+		for _, version := range versions {
+			refs = append(refs, apiRef{alias: alias, version: version})
+		}
+	}
+	return refs, nil
 }
 
 // getAPIs returns a slice of pointer to apimodel.API objects representing the
 // AWS service APIs listed in the models/apis/ directory of the aws-sdk-go
-// repository
+// repository. The APIs are loaded concurrently.
 func getAPIs(
 	filter *APIFilter,
 ) ([]*apimodel.API, error) {
-	sdkPath, err := ensureSDKRepo()
+	sdkHelper, err := getSDKHelper()
 	if err != nil {
 		return nil, err
 	}
-	sdkHelper := model.NewSDKHelper(sdkPath)
-	apis := []*apimodel.API{}
-
-	destPath := filepath.Join(sdkPath, "models", "apis")
-	apiDirs, err := ioutil.ReadDir(destPath)
+	refs, err := getAPIRefs(sdkHelper, filter)
 	if err != nil {
-		return apis, err
+		return nil, err
 	}
-	for _, f := range apiDirs {
-		fname := f.Name()
-		fp := filepath.Join(destPath, fname)
-		fi, err := os.Lstat(fp)
-		if err != nil {
-			return apis, err
-		}
-		if !fi.IsDir() {
-			continue
-		}
-		if filter != nil && len(filter.anyMatch) > 0 {
-			if !inStrings(fname, filter.anyMatch) {
-				continue
-			}
-		}
-		api, err := apimodel.New(fname, sdkHelper)
-		if err != nil {
-			return apis, err
-		}
+	loaded := make([]*apimodel.API, len(refs))
+	err = parallel(len(refs), func(x int) error {
+		api, err := apimodel.NewWithVersion(refs[x].alias, refs[x].version, sdkHelper)
+		loaded[x] = api
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	apis := []*apimodel.API{}
+	for _, api := range loaded {
 		if filter != nil && len(filter.anyProtocolMatch) > 0 {
 			if !inStrings(api.Protocol, filter.anyProtocolMatch) {
 				continue
@@ -88,12 +140,52 @@ func getAPIs(
 	return apis, nil
 }
 
+// parallel calls fn for each integer in [0, n) using a pool of at most
+// --workers goroutines. The first error returned by fn is returned once all
+// calls have finished.
+func parallel(n int, fn func(x int) error) error {
+	workers := cliWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	jobs := make(chan int)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := range jobs {
+				errs[x] = fn(x)
+			}
+		}()
+	}
+	for x := 0; x < n; x++ {
+		jobs <- x
+	}
+	close(jobs)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // getAPI returns a pointer to an apimodel.API object representing a
-// specified AWS service
+// specified AWS service. The API version supplied with --api-version is used,
+// defaulting to the newest API version of the service.
 func getAPI(
 	alias string,
 ) (*apimodel.API, error) {
-	apis, err := getAPIs(&APIFilter{anyMatch: []string{alias}})
+	apis, err := getAPIs(&APIFilter{
+		anyMatch:   []string{alias},
+		apiVersion: cliAPIVersion,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -103,17 +195,6 @@ func getAPI(
 	return apis[0], nil
 }
 
-// cloneSDKRepo git clone's the aws-sdk-go source repo into the cache and
-// returns the filepath to the clone'd repo
-func cloneSDKRepo(srcPath string) (string, error) {
-	clonePath := filepath.Join(srcPath, "aws-sdk-go")
-	if _, err := os.Stat(clonePath); os.IsNotExist(err) {
-		cmd := exec.Command("git", "clone", "--depth", "1", sdkRepoURL, clonePath)
-		return clonePath, cmd.Run()
-	}
-	return clonePath, nil
-}
-
 func inStrings(subject string, collection []string) bool {
 	if len(collection) == 0 {
 		return true
@@ -0,0 +1,349 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	sdkRepoURL = "https://github.com/aws/aws-sdk-go"
+	// cacheStateFileName is the name of the file in the cache directory root
+	// that records where the cached aws-sdk-go models came from
+	cacheStateFileName = "sdk-state.json"
+)
+
+var (
+	cliCacheUpdateUnpin bool
+)
+
+// cacheState records which aws-sdk-go commit the cached models come from
+type cacheState struct {
+	// RepoURL is the URL of the remote the cache was cloned/fetched from
+	RepoURL string `json:"repo_url"`
+	// Commit is the SHA of the aws-sdk-go commit checked out in the cache
+	Commit string `json:"commit"`
+	// PinnedRef is the tag or SHA the cache is pinned to, if any
+	PinnedRef string `json:"pinned_ref,omitempty"`
+	// UpdatedAt is when the cache was last cloned, updated or pinned
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// cacheCmd is the parent of the commands that manage the aws-sdk-go cache
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "manage the local cache of the aws-sdk-go repository",
+}
+
+// cacheStatusCmd shows which aws-sdk-go commit the cached models come from
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show which aws-sdk-go commit the cached API models come from",
+	Args:  cobra.NoArgs,
+	RunE:  cacheStatus,
+}
+
+// cacheUpdateCmd updates the cache to the latest aws-sdk-go commit
+var cacheUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update the cached aws-sdk-go repository to the latest commit",
+	Args:  cobra.NoArgs,
+	RunE:  cacheUpdate,
+}
+
+// cachePinCmd pins the cache to an aws-sdk-go tag or commit
+var cachePinCmd = &cobra.Command{
+	Use:   "pin <tag|sha>",
+	Short: "pin the cached aws-sdk-go repository to a tag or commit SHA",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("requires a <tag|sha> argument")
+		}
+		return nil
+	},
+	RunE: cachePin,
+}
+
+// cacheCleanCmd removes the cached aws-sdk-go repository
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "remove the cached aws-sdk-go repository",
+	Args:  cobra.NoArgs,
+	RunE:  cacheClean,
+}
+
+func init() {
+	cacheUpdateCmd.PersistentFlags().BoolVar(
+		&cliCacheUpdateUnpin, "unpin", false, "Remove any pin before updating to the latest commit.",
+	)
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheUpdateCmd)
+	cacheCmd.AddCommand(cachePinCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func cacheStatus(cmd *cobra.Command, args []string) error {
+	clonePath := sdkClonePath()
+	if !sdkRepoCloned() {
+		fmt.Printf("No aws-sdk-go clone in cache %s\n", cachePath)
+		return nil
+	}
+	commit, err := git(clonePath, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	commitDate, err := git(clonePath, "log", "-1", "--format=%cI")
+	if err != nil {
+		return err
+	}
+	remote, err := git(clonePath, "remote", "get-url", "origin")
+	if err != nil {
+		return err
+	}
+	state, err := readCacheState()
+	if err != nil {
+		return err
+	}
+	pinned := "(not pinned)"
+	if state != nil && state.PinnedRef != "" {
+		pinned = state.PinnedRef
+	}
+	fmt.Printf("Path:        %s\n", clonePath)
+	fmt.Printf("Remote:      %s\n", remote)
+	fmt.Printf("Commit:      %s\n", commit)
+	fmt.Printf("Commit date: %s\n", commitDate)
+	fmt.Printf("Pinned to:   %s\n", pinned)
+	if _, err := os.Stat(apiIndexPath(commit)); err == nil {
+		fmt.Printf("API index:   %s\n", apiIndexPath(commit))
+	} else {
+		fmt.Printf("API index:   (not built)\n")
+	}
+	if state != nil {
+		fmt.Printf("Updated at:  %s\n", state.UpdatedAt.Format(time.RFC3339))
+		if state.Commit != commit {
+			fmt.Printf(
+				"WARNING: cache state records commit %s but %s is checked out\n",
+				state.Commit, commit,
+			)
+		}
+	}
+	return nil
+}
+
+func cacheUpdate(cmd *cobra.Command, args []string) error {
+	state, err := readCacheState()
+	if err != nil {
+		return err
+	}
+	if state != nil && state.PinnedRef != "" && !cliCacheUpdateUnpin {
+		return fmt.Errorf(
+			"cache is pinned to %s. Use `cache pin` to pin to a different "+
+				"ref or `cache update --unpin` to update to the latest commit",
+			state.PinnedRef,
+		)
+	}
+	if _, err = ensureSDKRepo(); err != nil {
+		return err
+	}
+	if err = checkoutSDKRef("HEAD"); err != nil {
+		return err
+	}
+	state, err = writeCacheState("")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated aws-sdk-go cache to %s\n", state.Commit)
+	return nil
+}
+
+func cachePin(cmd *cobra.Command, args []string) error {
+	ref := args[0]
+	if _, err := ensureSDKRepo(); err != nil {
+		return err
+	}
+	if err := checkoutSDKRef(ref); err != nil {
+		return err
+	}
+	state, err := writeCacheState(ref)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pinned aws-sdk-go cache to %s (%s)\n", ref, state.Commit)
+	return nil
+}
+
+func cacheClean(cmd *cobra.Command, args []string) error {
+	clonePath := sdkClonePath()
+	trace("removing %s ...\n", clonePath)
+	if err := os.RemoveAll(clonePath); err != nil {
+		return err
+	}
+	statePath := filepath.Join(cachePath, cacheStateFileName)
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := removeAPIIndexes(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed aws-sdk-go cache %s\n", clonePath)
+	return nil
+}
+
+// sdkClonePath returns the filepath to the aws-sdk-go clone in the cache
+func sdkClonePath() string {
+	return filepath.Join(cachePath, "src", "aws-sdk-go")
+}
+
+// sdkRepoCloned returns true if the cache contains a clone of aws-sdk-go
+func sdkRepoCloned() bool {
+	_, err := os.Stat(filepath.Join(sdkClonePath(), ".git"))
+	return err == nil
+}
+
+func ensureSDKRepo() (string, error) {
+	srcPath := filepath.Join(cachePath, "src")
+	if err := os.MkdirAll(srcPath, os.ModePerm); err != nil {
+		return "", err
+	}
+	// clone the aws-sdk-go repository locally so we can query for API
+	// information in the models/apis/ directories
+	clonePath, err := cloneSDKRepo(srcPath)
+	if err != nil {
+		return "", err
+	}
+	return clonePath, nil
+}
+
+// cloneSDKRepo git clone's the aws-sdk-go source repo into the cache and
+// returns the filepath to the clone'd repo. If the cache state records a
+// pinned ref (e.g. because the clone was removed by hand), the pinned ref is
+// checked out after cloning.
+func cloneSDKRepo(srcPath string) (string, error) {
+	clonePath := filepath.Join(srcPath, "aws-sdk-go")
+	if _, err := os.Stat(clonePath); os.IsNotExist(err) {
+		trace("cloning %s to local cache %s ...\n", sdkRepo, srcPath)
+		_, err := git("", "clone", "--depth", "1", sdkRepo, clonePath)
+		if err != nil {
+			// Don't leave a partial clone around to be mistaken for a good
+			// one on the next run
+			os.RemoveAll(clonePath)
+			return "", err
+		}
+		state, err := readCacheState()
+		if err != nil {
+			return "", err
+		}
+		pinnedRef := ""
+		if state != nil && state.PinnedRef != "" {
+			pinnedRef = state.PinnedRef
+			if err = checkoutSDKRef(pinnedRef); err != nil {
+				return "", err
+			}
+		}
+		if _, err = writeCacheState(pinnedRef); err != nil {
+			return "", err
+		}
+	}
+	apisPath := filepath.Join(clonePath, "models", "apis")
+	if _, err := os.Stat(apisPath); os.IsNotExist(err) {
+		return "", fmt.Errorf(
+			"expected to find %s in cached aws-sdk-go clone. Run `cache "+
+				"clean` and try again or use --models-dir to point at a "+
+				"local models directory", apisPath,
+		)
+	}
+	return clonePath, nil
+}
+
+// checkoutSDKRef fetches a ref (tag, SHA or "HEAD" for the remote's default
+// branch) from the configured aws-sdk-go remote into the cached clone and
+// checks it out
+func checkoutSDKRef(ref string) error {
+	clonePath := sdkClonePath()
+	if _, err := git(clonePath, "remote", "set-url", "origin", sdkRepo); err != nil {
+		return err
+	}
+	trace("fetching %s from %s ...\n", ref, sdkRepo)
+	if _, err := git(clonePath, "fetch", "--depth", "1", "origin", ref); err != nil {
+		return fmt.Errorf(
+			"unable to fetch %s. Note that commit SHAs must be given in "+
+				"full: %v", ref, err,
+		)
+	}
+	_, err := git(clonePath, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+	return err
+}
+
+// readCacheState returns the recorded cache state or nil if no state has been
+// recorded
+func readCacheState() (*cacheState, error) {
+	b, err := ioutil.ReadFile(filepath.Join(cachePath, cacheStateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state cacheState
+	if err = json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode cache state: %v", err)
+	}
+	return &state, nil
+}
+
+// writeCacheState records the commit currently checked out in the cached clone
+// along with the ref it is pinned to, if any
+func writeCacheState(pinnedRef string) (*cacheState, error) {
+	commit, err := git(sdkClonePath(), "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	state := &cacheState{
+		RepoURL:   sdkRepo,
+		Commit:    commit,
+		PinnedRef: pinnedRef,
+		UpdatedAt: time.Now().UTC(),
+	}
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	statePath := filepath.Join(cachePath, cacheStateFileName)
+	if err = ioutil.WriteFile(statePath, b, 0644); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// git runs a git command in dir and returns its trimmed stdout. On failure,
+// the returned error includes git's stderr.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(
+			"git %s failed: %v: %s",
+			strings.Join(args, " "), err, strings.TrimSpace(stderr.String()),
+		)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
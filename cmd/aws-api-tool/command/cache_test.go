@@ -0,0 +1,165 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitIdentity are the git options used to commit to the test repositories
+// regardless of the user's git configuration
+var gitIdentity = []string{
+	"-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+}
+
+// newSDKRemote returns the file:// URL of a bare git repository standing in
+// for aws-sdk-go, with a commit tagged v1.0.0 and a later commit on main,
+// along with the SHAs of both commits
+func newSDKRemote(t *testing.T, dir string) (string, string, string) {
+	t.Helper()
+	mustGit := func(dir string, args ...string) string {
+		t.Helper()
+		out, err := git(dir, args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+	remotePath := filepath.Join(dir, "aws-sdk-go.git")
+	workPath := filepath.Join(dir, "work")
+	mustGit(dir, "init", "--quiet", "--bare", "--initial-branch=main", remotePath)
+	mustGit(dir, "init", "--quiet", "--initial-branch=main", workPath)
+	modelPath := filepath.Join(workPath, "models", "apis", "sqs", "2012-11-05", "api-2.json")
+	if err := os.MkdirAll(filepath.Dir(modelPath), os.ModePerm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	shas := []string{}
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		contents := `{"metadata": {"serviceId": "SQS", "version": "` + version + `"}}`
+		if err := ioutil.WriteFile(modelPath, []byte(contents), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mustGit(workPath, "add", "-A")
+		mustGit(workPath, append(gitIdentity, "commit", "--quiet", "-m", "Release v"+version)...)
+		shas = append(shas, mustGit(workPath, "rev-parse", "HEAD"))
+		if version == "1.0.0" {
+			mustGit(workPath, "tag", "v"+version)
+		}
+	}
+	mustGit(workPath, "push", "--quiet", "--tags", remotePath, "main")
+	return "file://" + remotePath, shas[0], shas[1]
+}
+
+// runCommand runs the aws-api-tool command line with the supplied arguments
+// and returns what it printed to stdout
+func runCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	// Flags keep their values between executions of the commands
+	cliCacheUpdateUnpin = false
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	rootCmd.SetArgs(args)
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
+	err = rootCmd.Execute()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := ioutil.ReadAll(r)
+	r.Close()
+	return string(out), err
+}
+
+func TestCacheCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-api-tool")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	remoteURL, taggedSHA, headSHA := newSDKRemote(t, dir)
+	flags := []string{"--cache-path", filepath.Join(dir, "cache"), "--sdk-repo-url", remoteURL}
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		return runCommand(t, append(append([]string{}, flags...), args...)...)
+	}
+	// status returns the Commit and Pinned to values shown by cache status
+	status := func() (string, string) {
+		t.Helper()
+		out, err := run("cache", "status")
+		if err != nil {
+			t.Fatalf("cache status: unexpected error: %v", err)
+		}
+		fields := map[string]string{}
+		for _, line := range strings.Split(out, "\n") {
+			if kv := strings.SplitN(line, ":", 2); len(kv) == 2 {
+				fields[kv[0]] = strings.TrimSpace(kv[1])
+			}
+		}
+		return fields["Commit"], fields["Pinned to"]
+	}
+
+	if out, _ := run("cache", "status"); !strings.HasPrefix(out, "No aws-sdk-go clone") {
+		t.Errorf("expected no clone before pinning, got %q", out)
+	}
+
+	// Pinning by tag clones the remote and checks out the tagged commit
+	if _, err := run("cache", "pin", "v1.0.0"); err != nil {
+		t.Fatalf("cache pin v1.0.0: unexpected error: %v", err)
+	}
+	if commit, pinned := status(); commit != taggedSHA || pinned != "v1.0.0" {
+		t.Errorf("expected commit %s pinned to v1.0.0, got %s pinned to %s", taggedSHA, commit, pinned)
+	}
+
+	// Updating a pinned cache is refused unless the pin is removed
+	if _, err := run("cache", "update"); err == nil || !strings.Contains(err.Error(), "pinned to v1.0.0") {
+		t.Errorf("expected cache update to be refused while pinned, got %v", err)
+	}
+	if commit, _ := status(); commit != taggedSHA {
+		t.Errorf("expected commit %s after refused update, got %s", taggedSHA, commit)
+	}
+
+	// Pinning by full SHA
+	if _, err := run("cache", "pin", headSHA); err != nil {
+		t.Fatalf("cache pin %s: unexpected error: %v", headSHA, err)
+	}
+	if commit, pinned := status(); commit != headSHA || pinned != headSHA {
+		t.Errorf("expected commit %s pinned to it, got %s pinned to %s", headSHA, commit, pinned)
+	}
+	if _, err := run("cache", "pin", taggedSHA); err != nil {
+		t.Fatalf("cache pin %s: unexpected error: %v", taggedSHA, err)
+	}
+
+	// Updating with --unpin removes the pin and checks out the latest
+	// commit of the remote's default branch
+	if _, err := run("cache", "update", "--unpin"); err != nil {
+		t.Fatalf("cache update --unpin: unexpected error: %v", err)
+	}
+	if commit, pinned := status(); commit != headSHA || pinned != "(not pinned)" {
+		t.Errorf("expected commit %s not pinned, got %s pinned to %s", headSHA, commit, pinned)
+	}
+
+	// Cleaning removes the clone and the cache state
+	if _, err := run("cache", "clean"); err != nil {
+		t.Fatalf("cache clean: unexpected error: %v", err)
+	}
+	if _, err := os.Stat(sdkClonePath()); !os.IsNotExist(err) {
+		t.Errorf("expected the clone to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cachePath, cacheStateFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the cache state to be removed, got %v", err)
+	}
+	if out, _ := run("cache", "status"); !strings.HasPrefix(out, "No aws-sdk-go clone") {
+		t.Errorf("expected no clone after cleaning, got %q", out)
+	}
+}
@@ -0,0 +1,104 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/jaypipes/aws-api-tools/pkg/apimodel"
+)
+
+// describeResourceCmd shows the operations that manage a resource of an AWS
+// API service
+var describeResourceCmd = &cobra.Command{
+	Use:     "describe-resource <api> <resource>",
+	Aliases: []string{"resource"},
+	Short:   "shows the operations that manage a Resource of an AWS service API",
+	Args:    requireAPIAndResourceArgs,
+	RunE:    describeResource,
+}
+
+func init() {
+	addAPIVersionFlag(describeResourceCmd)
+	rootCmd.AddCommand(describeResourceCmd)
+}
+
+func requireAPIAndResourceArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("requires <api> and <resource> arguments")
+	}
+	return nil
+}
+
+func describeResource(cmd *cobra.Command, args []string) error {
+	api, err := getAPI(args[0])
+	if err != nil {
+		return err
+	}
+	resource := api.GetResource(args[1])
+	if resource == nil {
+		return fmt.Errorf("no such resource %s in API %s", args[1], args[0])
+	}
+	arnIdentified := "no"
+	if resource.ARNIdentified() {
+		arnIdentified = "yes"
+	}
+	fmt.Printf("Singular name:      %s\n", resource.SingularName)
+	fmt.Printf("Plural name:        %s\n", resource.PluralName)
+	fmt.Printf("Identifiers:        %s\n", strings.Join(resource.Identifiers, ", "))
+	fmt.Printf("Primary identifier: %s\n", resource.PrimaryIdentifier)
+	fmt.Printf("ARN identified:     %s\n", arnIdentified)
+	if resource.Parent != nil {
+		fmt.Printf("Parent:             %s\n", resource.Parent.SingularName)
+	}
+	if len(resource.Children) > 0 {
+		children := make([]string, len(resource.Children))
+		for x, child := range resource.Children {
+			children[x] = child.SingularName
+		}
+		fmt.Printf("Children:           %s\n", strings.Join(children, ", "))
+	}
+	identifiers := map[string]string{
+		apimodel.OperationTypeReadOne: resource.ReadOneIdentifier,
+		apimodel.OperationTypeDelete:  resource.DeleteIdentifier,
+	}
+	headers := []string{"Type", "Operation", "HTTP Method", "Identifier"}
+	rows := [][]string{}
+	for _, opType := range apimodel.OperationTypes {
+		op := resource.Operation(opType)
+		if op == nil {
+			rows = append(rows, []string{opType, "", "", ""})
+			continue
+		}
+		rows = append(rows, []string{opType, op.Name, op.Method, identifiers[opType]})
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(headers)
+	table.AppendBulk(rows)
+	table.Render()
+	if missing := resource.MissingOperationTypes(); len(missing) > 0 {
+		fmt.Printf("Missing operations: %s\n", strings.Join(missing, ", "))
+	}
+	for _, mismatch := range resource.IdentifierMismatches() {
+		supplied := ""
+		if mismatch.Supplied {
+			supplied = fmt.Sprintf(" (supplied to %s)", resource.Create.Name)
+		}
+		fmt.Printf(
+			"Identifier mismatch: %s takes %s%s, not %s returned by %s\n",
+			mismatch.Operation, mismatch.Identifier, supplied,
+			strings.Join(resource.Identifiers, " or "), resource.Create.Name,
+		)
+	}
+	return nil
+}
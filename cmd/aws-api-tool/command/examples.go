@@ -0,0 +1,74 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cliExamplesOutputFormat string
+)
+
+// showExamplesCmd shows the examples for an AWS API service operation
+var showExamplesCmd = &cobra.Command{
+	Use:     "show-examples <api> <operation>",
+	Aliases: []string{"examples"},
+	Short:   "shows example requests and responses for an AWS service API operation",
+	Args:    requireAPIAndOperationArgs,
+	RunE:    showExamples,
+}
+
+func init() {
+	showExamplesCmd.PersistentFlags().StringVarP(
+		&cliExamplesOutputFormat, "format", "f", "yaml", "Output format for examples ('yaml' or 'json').",
+	)
+	addAPIVersionFlag(showExamplesCmd)
+	rootCmd.AddCommand(showExamplesCmd)
+}
+
+func requireAPIAndOperationArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("requires <api> and <operation> arguments")
+	}
+	return nil
+}
+
+func showExamples(cmd *cobra.Command, args []string) error {
+	api, err := getAPI(args[0])
+	if err != nil {
+		return err
+	}
+	opName := args[1]
+	if api.GetOperation(opName) == nil {
+		return fmt.Errorf("no such operation %s in API %s", opName, args[0])
+	}
+	examples := api.GetExamples(opName)
+	if len(examples) == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+	b, err := json.MarshalIndent(examples, "", "  ")
+	if err != nil {
+		return err
+	}
+	if cliExamplesOutputFormat == "yaml" {
+		yamlStr, err := yaml.JSONToYAML(b)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(yamlStr))
+	} else {
+		fmt.Println(string(b))
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jaypipes/aws-api-tools/pkg/apimodel"
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const (
+	// indexFormatVersion must be bumped whenever apimodel.Summary changes so
+	// that indexes written by older versions of aws-api-tool get rebuilt
+	indexFormatVersion = 4
+	// indexDirName is the name of the directory in the cache directory root
+	// that holds the API indexes, one per aws-sdk-go commit
+	indexDirName = "index"
+)
+
+// apiIndex is a persistent index of summary information about every API
+// version of every service in an aws-sdk-go commit. It lets commands like
+// list-apis and info avoid loading and evaluating every API model.
+type apiIndex struct {
+	FormatVersion int `json:"format_version"`
+	// Commit is the aws-sdk-go commit the index was built from
+	Commit string `json:"commit"`
+	// APIs contains a summary for every API version of every service, sorted
+	// by service alias and then from oldest to newest API version
+	APIs []*apimodel.Summary `json:"apis"`
+}
+
+// getAPISummaries returns summaries of the APIs matching the supplied filter.
+// When reading API models from the aws-sdk-go clone in the cache, the
+// summaries are read from the index for the cached commit, building the index
+// first if needed. Otherwise, the APIs are loaded and summarized directly.
+func getAPISummaries(filter *APIFilter) ([]*apimodel.Summary, error) {
+	if modelsDir != "" || modelsArchive != "" {
+		apis, err := getAPIs(filter)
+		if err != nil {
+			return nil, err
+		}
+		summaries := make([]*apimodel.Summary, len(apis))
+		err = parallel(len(apis), func(x int) error {
+			summaries[x] = apis[x].Summary()
+			return nil
+		})
+		return summaries, err
+	}
+	sdkPath, err := ensureSDKRepo()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := git(sdkPath, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	index, err := loadAPIIndex(commit)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		index, err = buildAPIIndex(model.NewSDKHelper(sdkPath), commit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return index.filter(filter)
+}
+
+// loadAPIIndex returns the index for the supplied aws-sdk-go commit or nil if
+// there is no such index or it was written in an older format
+func loadAPIIndex(commit string) (*apiIndex, error) {
+	b, err := ioutil.ReadFile(apiIndexPath(commit))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var index apiIndex
+	if err = json.Unmarshal(b, &index); err != nil {
+		trace("ignoring corrupt API index %s: %v\n", apiIndexPath(commit), err)
+		return nil, nil
+	}
+	if index.FormatVersion != indexFormatVersion || index.Commit != commit {
+		return nil, nil
+	}
+	return &index, nil
+}
+
+// buildAPIIndex loads and summarizes every API version of every service
+// concurrently and writes the resulting index to the cache, replacing the
+// indexes for any other aws-sdk-go commits
+func buildAPIIndex(
+	sdkHelper *model.SDKHelper,
+	commit string,
+) (*apiIndex, error) {
+	trace("building API index for aws-sdk-go commit %s ...\n", commit)
+	refs, err := getAPIRefs(sdkHelper, &APIFilter{allVersions: true})
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*apimodel.Summary, len(refs))
+	err = parallel(len(refs), func(x int) error {
+		api, err := apimodel.NewWithVersion(refs[x].alias, refs[x].version, sdkHelper)
+		if err != nil {
+			return err
+		}
+		summaries[x] = api.Summary()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	index := &apiIndex{
+		FormatVersion: indexFormatVersion,
+		Commit:        commit,
+		APIs:          summaries,
+	}
+	if err = removeAPIIndexes(); err != nil {
+		return nil, err
+	}
+	indexDir := filepath.Join(cachePath, indexDirName)
+	if err = os.MkdirAll(indexDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(apiIndexPath(commit), b, 0644); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// filter returns the summaries matching the supplied filter, mirroring the
+// way getAPIs selects service aliases and API versions
+func (i *apiIndex) filter(filter *APIFilter) ([]*apimodel.Summary, error) {
+	// Summaries are sorted from oldest to newest API version, so the last
+	// summary seen for an alias is the newest
+	newest := map[string]*apimodel.Summary{}
+	for _, s := range i.APIs {
+		newest[s.AliasLower] = s
+	}
+	res := []*apimodel.Summary{}
+	for _, s := range i.APIs {
+		if filter != nil && len(filter.anyMatch) > 0 {
+			if !inStrings(s.AliasLower, filter.anyMatch) {
+				continue
+			}
+		}
+		switch {
+		case filter != nil && filter.allVersions:
+		case filter != nil && filter.apiVersion != "":
+			if s.Version != filter.apiVersion {
+				continue
+			}
+		default:
+			if newest[s.AliasLower] != s {
+				continue
+			}
+		}
+		if filter != nil && len(filter.anyProtocolMatch) > 0 {
+			if !inStrings(s.Protocol, filter.anyProtocolMatch) {
+				continue
+			}
+		}
+		res = append(res, s)
+	}
+	if len(res) == 0 && filter != nil && filter.apiVersion != "" {
+		return nil, fmt.Errorf(
+			"%w: %s", model.ErrAPIVersionNotFound, filter.apiVersion,
+		)
+	}
+	return res, nil
+}
+
+// apiIndexPath returns the path to the index for an aws-sdk-go commit
+func apiIndexPath(commit string) string {
+	return filepath.Join(cachePath, indexDirName, commit+".json")
+}
+
+// removeAPIIndexes removes the indexes for all aws-sdk-go commits
+func removeAPIIndexes() error {
+	return os.RemoveAll(filepath.Join(cachePath, indexDirName))
+}
@@ -0,0 +1,160 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const sqsModel = `{
+  "metadata": {
+    "apiVersion": "2012-11-05",
+    "endpointPrefix": "sqs",
+    "protocol": "query",
+    "serviceFullName": "Amazon Simple Queue Service",
+    "serviceId": "SQS",
+    "signatureVersion": "v4",
+    "xmlNamespace": "http://queue.amazonaws.com/doc/2012-11-05/"
+  },
+  "operations": {
+    "DeleteQueue": {
+      "name": "DeleteQueue",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DeleteQueueRequest"}
+    }
+  },
+  "shapes": {
+    "DeleteQueueRequest": {
+      "type": "structure",
+      "required": ["QueueUrl"],
+      "members": {"QueueUrl": {"shape": "String"}}
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+func TestParallel(t *testing.T) {
+	defer func(workers int) { cliWorkers = workers }(cliWorkers)
+	cliWorkers = 3
+	var mu sync.Mutex
+	called := map[int]bool{}
+	var running, maxRunning int32
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	err := parallel(20, func(x int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		mu.Lock()
+		called[x] = true
+		mu.Unlock()
+		switch x {
+		case 7:
+			return errFirst
+		case 13:
+			return errSecond
+		}
+		return nil
+	})
+	// The error of the first failing call is returned, but only once every
+	// call has been made
+	if err != errFirst {
+		t.Errorf("expected error %v, got %v", errFirst, err)
+	}
+	if len(called) != 20 {
+		t.Errorf("expected 20 calls, got %d", len(called))
+	}
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", maxRunning)
+	}
+
+	for _, workers := range []int{0, 1, 100} {
+		cliWorkers = workers
+		calls := int32(0)
+		if err := parallel(5, func(x int) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}); err != nil || calls != 5 {
+			t.Errorf("%d workers: expected 5 calls and no error, got %d calls and %v", workers, calls, err)
+		}
+	}
+	if err := parallel(0, func(x int) error { return errFirst }); err != nil {
+		t.Errorf("expected no error without calls, got %v", err)
+	}
+}
+
+func TestAPIIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-api-tool")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(path string) { cachePath = path }(cachePath)
+	cachePath = dir
+
+	src := model.NewMemorySource()
+	src.Add("sqs", "2012-11-05", model.ModelFile, []byte(sqsModel))
+	sdkHelper := model.NewSDKHelperFromSource(src)
+
+	if index, err := loadAPIIndex("c1"); err != nil || index != nil {
+		t.Fatalf("expected no index before building one, got %v, %v", index, err)
+	}
+	if _, err := buildAPIIndex(sdkHelper, "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	index, err := loadAPIIndex("c1")
+	if err != nil || index == nil {
+		t.Fatalf("expected the index for c1, got %v, %v", index, err)
+	}
+	if len(index.APIs) != 1 || index.APIs[0].AliasLower != "sqs" || index.APIs[0].Operations != 1 {
+		t.Errorf("expected a summary of the sqs API, got %+v", index.APIs)
+	}
+
+	// An index is only used for the commit it was built from
+	if index, err := loadAPIIndex("c2"); err != nil || index != nil {
+		t.Errorf("expected no index for c2, got %v, %v", index, err)
+	}
+	// Building the index for another commit replaces the other indexes
+	if _, err := buildAPIIndex(sdkHelper, "c2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(apiIndexPath("c1")); !os.IsNotExist(err) {
+		t.Errorf("expected the index for c1 to be removed, got %v", err)
+	}
+
+	// Indexes written in another format, for another commit than their
+	// file name says, or that cannot be decoded are ignored
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"format", fmt.Sprintf(`{"format_version": %d, "commit": "c2", "apis": []}`, indexFormatVersion-1)},
+		{"commit", fmt.Sprintf(`{"format_version": %d, "commit": "c1", "apis": []}`, indexFormatVersion)},
+		{"corrupt", `{"format_version":`},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(apiIndexPath("c2"), []byte(test.contents), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if index, err := loadAPIIndex("c2"); err != nil || index != nil {
+			t.Errorf("%s: expected the index to be ignored, got %v, %v", test.name, index, err)
+		}
+	}
+}
@@ -9,7 +9,6 @@ package command
 import (
 	"fmt"
 
-	"github.com/jaypipes/aws-api-tools/pkg/apimodel"
 	"github.com/spf13/cobra"
 )
 
@@ -22,40 +21,32 @@ var infoCmd = &cobra.Command{
 }
 
 func init() {
+	addAPIVersionFlag(infoCmd)
 	rootCmd.AddCommand(infoCmd)
 }
 
 func infoAPI(cmd *cobra.Command, args []string) error {
-	api, err := getAPI(args[0])
+	summaries, err := getAPISummaries(&APIFilter{
+		anyMatch:   []string{args[0]},
+		apiVersion: cliAPIVersion,
+	})
 	if err != nil {
 		return err
 	}
-	ops := api.GetOperations(nil)
-	objects := api.GetObjects(nil)
-	countScalars := 0
-	countPayloads := 0
-	countExceptions := 0
-	countLists := 0
-	for _, obj := range objects {
-		switch obj.Type {
-		case apimodel.ObjectTypeScalar:
-			countScalars++
-		case apimodel.ObjectTypeList:
-			countLists++
-		case apimodel.ObjectTypePayload:
-			countPayloads++
-		case apimodel.ObjectTypeException:
-			countExceptions++
-		}
+	if len(summaries) == 0 {
+		return fmt.Errorf("unknown API %s", args[0])
 	}
-	fmt.Printf("Full name:        %s\n", api.FullName)
-	fmt.Printf("API version:      %s\n", api.Version)
-	fmt.Printf("Protocol:         %s\n", api.Protocol)
-	fmt.Printf("Total operations: %d\n", len(ops))
-	fmt.Printf("Total objects:    %d\n", len(objects))
-	fmt.Printf("Total scalars:    %d\n", countScalars)
-	fmt.Printf("Total payloads:   %d\n", countPayloads)
-	fmt.Printf("Total exceptions: %d\n", countExceptions)
-	fmt.Printf("Total lists:      %d\n", countLists)
+	summary := summaries[0]
+	fmt.Printf("Full name:        %s\n", summary.FullName)
+	fmt.Printf("API version:      %s\n", summary.Version)
+	fmt.Printf("Protocol:         %s\n", summary.Protocol)
+	fmt.Printf("Total operations: %d\n", summary.Operations)
+	fmt.Printf("Total resources:  %d\n", summary.Resources)
+	fmt.Printf("Total objects:    %d\n", summary.Objects)
+	fmt.Printf("Total scalars:    %d\n", summary.Scalars)
+	fmt.Printf("Total payloads:   %d\n", summary.Payloads)
+	fmt.Printf("Total exceptions: %d\n", summary.Exceptions)
+	fmt.Printf("Total lists:      %d\n", summary.Lists)
+	fmt.Printf("Total maps:       %d\n", summary.Maps)
 	return nil
 }
@@ -8,8 +8,10 @@ package command
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jaypipes/aws-api-tools/pkg/apimodel"
@@ -20,10 +22,13 @@ import (
 var (
 	cliListAPIsFilter                 string
 	cliListAPIsProtocolFilter         string
+	cliListAPIsAllVersions            bool
 	cliListOperationsHTTPMethodFilter string
 	cliListOperationsPrefixFilter     string
 	cliListObjectsTypeFilter          string
 	cliListObjectsPrefixFilter        string
+	cliListEndpointsPartitionFilter   string
+	cliListResourcesTree              bool
 )
 
 // listAPIsCmd lists AWS service APIs
@@ -50,6 +55,33 @@ var listOperationsCmd = &cobra.Command{
 	RunE:    listOperations,
 }
 
+// listPaginatorsCmd lists the paginators for an AWS API service
+var listPaginatorsCmd = &cobra.Command{
+	Use:     "list-paginators <api>",
+	Aliases: []string{"paginators"},
+	Short:   "lists Paginators for an AWS service API",
+	Args:    requireAPIArg,
+	RunE:    listPaginators,
+}
+
+// listWaitersCmd lists the waiters for an AWS API service
+var listWaitersCmd = &cobra.Command{
+	Use:     "list-waiters <api>",
+	Aliases: []string{"waiters"},
+	Short:   "lists Waiters for an AWS service API",
+	Args:    requireAPIArg,
+	RunE:    listWaiters,
+}
+
+// listResourcesCmd lists the resource objects of an AWS API service
+var listResourcesCmd = &cobra.Command{
+	Use:     "list-resources <api>",
+	Aliases: []string{"resources"},
+	Short:   "lists Resource objects for an AWS service API",
+	Args:    requireAPIArg,
+	RunE:    listResources,
+}
+
 // listObjectsCmd lists all object types for an AWS API service
 var listObjectsCmd = &cobra.Command{
 	Use:     "list-objects <api>",
@@ -59,6 +91,15 @@ var listObjectsCmd = &cobra.Command{
 	RunE:    listObjects,
 }
 
+// listEndpointsCmd lists the endpoints of an AWS API service
+var listEndpointsCmd = &cobra.Command{
+	Use:     "list-endpoints <api>",
+	Aliases: []string{"endpoints"},
+	Short:   "lists Endpoints for an AWS service API",
+	Args:    requireAPIArg,
+	RunE:    listEndpoints,
+}
+
 func init() {
 	listAPIsCmd.PersistentFlags().StringVarP(
 		&cliListAPIsFilter, "filter", "f", "", "Comma-delimited list of strings to filter APIs on.",
@@ -66,6 +107,9 @@ func init() {
 	listAPIsCmd.PersistentFlags().StringVar(
 		&cliListAPIsProtocolFilter, "protocol", "", "Comma-delimited list of protocol/schemes to filter APIs on.",
 	)
+	listAPIsCmd.PersistentFlags().BoolVar(
+		&cliListAPIsAllVersions, "all-versions", false, "List every API version of each service instead of only the newest.",
+	)
 	listOperationsCmd.PersistentFlags().StringVarP(
 		&cliListOperationsPrefixFilter, "prefix", "p", "", "Comma-delimited list of string prefixes to filter operations by.",
 	)
@@ -78,15 +122,31 @@ func init() {
 	listObjectsCmd.PersistentFlags().StringVarP(
 		&cliListObjectsTypeFilter, "type", "t", "", "Comma-delimited list of object types to filter objects by.",
 	)
+	listResourcesCmd.PersistentFlags().BoolVar(
+		&cliListResourcesTree, "tree", false, "Show the resources contained within each resource as a tree.",
+	)
+	listEndpointsCmd.PersistentFlags().StringVar(
+		&cliListEndpointsPartitionFilter, "partition", "", "Comma-delimited list of partitions to filter endpoints by.",
+	)
+	addAPIVersionFlag(listOperationsCmd)
+	addAPIVersionFlag(listResourcesCmd)
+	addAPIVersionFlag(listObjectsCmd)
+	addAPIVersionFlag(listPaginatorsCmd)
+	addAPIVersionFlag(listWaitersCmd)
+	addAPIVersionFlag(listEndpointsCmd)
 	rootCmd.AddCommand(listAPIsCmd)
 	rootCmd.AddCommand(listOperationsCmd)
+	rootCmd.AddCommand(listResourcesCmd)
 	rootCmd.AddCommand(listObjectsCmd)
+	rootCmd.AddCommand(listPaginatorsCmd)
+	rootCmd.AddCommand(listWaitersCmd)
+	rootCmd.AddCommand(listEndpointsCmd)
 }
 
 func listAPIs(cmd *cobra.Command, args []string) error {
 	var filter *APIFilter
-	if cliListAPIsFilter != "" || cliListAPIsProtocolFilter != "" {
-		filter = &APIFilter{}
+	if cliListAPIsFilter != "" || cliListAPIsProtocolFilter != "" || cliListAPIsAllVersions {
+		filter = &APIFilter{allVersions: cliListAPIsAllVersions}
 		if cliListAPIsFilter != "" {
 			filter.anyMatch = strings.Split(cliListAPIsFilter, ",")
 		}
@@ -94,7 +154,7 @@ func listAPIs(cmd *cobra.Command, args []string) error {
 			filter.anyProtocolMatch = strings.Split(cliListAPIsProtocolFilter, ",")
 		}
 	}
-	apis, err := getAPIs(filter)
+	apis, err := getAPISummaries(filter)
 	if err != nil {
 		return err
 	}
@@ -131,10 +191,14 @@ func listOperations(cmd *cobra.Command, args []string) error {
 		filter.Prefixes = strings.Split(cliListOperationsPrefixFilter, ",")
 	}
 	operations := api.GetOperations(filter)
-	headers := []string{"Name", "HTTP Method"}
+	headers := []string{"Name", "HTTP Method", "Paginated"}
 	rows := make([][]string, len(operations))
 	for x, operation := range operations {
-		rows[x] = []string{operation.Name, operation.Method}
+		paginated := ""
+		if operation.Paginated {
+			paginated = "yes"
+		}
+		rows[x] = []string{operation.Name, operation.Method, paginated}
 	}
 	noResults(rows)
 	sort.Slice(rows, func(i, j int) bool {
@@ -147,6 +211,49 @@ func listOperations(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func listResources(cmd *cobra.Command, args []string) error {
+	api, err := getAPI(args[0])
+	if err != nil {
+		return err
+	}
+	resources := api.GetTopLevelResources()
+	if cliListResourcesTree {
+		if len(resources) == 0 {
+			fmt.Println("No results found.")
+		}
+		for _, resource := range resources {
+			fmt.Println(resource.SingularName)
+			printResourceTree(resource.Children, "")
+		}
+		return nil
+	}
+	headers := []string{"Name"}
+	rows := make([][]string, len(resources))
+	for x, resource := range resources {
+		rows[x] = []string{resource.SingularName}
+	}
+	noResults(rows)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(headers)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
+
+// printResourceTree prints the supplied resources, and the resources contained
+// within them, as the branches of a tree, each line starting with the supplied
+// indentation
+func printResourceTree(resources []*apimodel.Resource, indent string) {
+	for x, resource := range resources {
+		branch, nextIndent := "├── ", "│   "
+		if x == len(resources)-1 {
+			branch, nextIndent = "└── ", "    "
+		}
+		fmt.Println(indent + branch + resource.SingularName)
+		printResourceTree(resource.Children, indent+nextIndent)
+	}
+}
+
 func listObjects(cmd *cobra.Command, args []string) error {
 	api, err := getAPI(args[0])
 	if err != nil {
@@ -179,3 +286,113 @@ func listObjects(cmd *cobra.Command, args []string) error {
 	table.Render()
 	return nil
 }
+
+func listPaginators(cmd *cobra.Command, args []string) error {
+	api, err := getAPI(args[0])
+	if err != nil {
+		return err
+	}
+	paginators := api.GetPaginators()
+	headers := []string{"Operation", "Input Token", "Output Token", "Limit Key", "Result Key"}
+	rows := make([][]string, len(paginators))
+	for x, pag := range paginators {
+		rows[x] = []string{
+			pag.Operation,
+			strings.Join(pag.InputTokens, ", "),
+			strings.Join(pag.OutputTokens, ", "),
+			pag.LimitKey,
+			strings.Join(pag.ResultKeys, ", "),
+		}
+	}
+	noResults(rows)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(headers)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
+
+func listWaiters(cmd *cobra.Command, args []string) error {
+	api, err := getAPI(args[0])
+	if err != nil {
+		return err
+	}
+	waiters := api.GetWaiters()
+	headers := []string{"Waiter", "Operation", "Delay", "Max Attempts", "Acceptors"}
+	rows := make([][]string, len(waiters))
+	for x, waiter := range waiters {
+		acceptors := make([]string, len(waiter.Acceptors))
+		for y, acc := range waiter.Acceptors {
+			match := fmt.Sprintf("%s == %v", acc.Matcher, acc.Expected)
+			if acc.Argument != "" {
+				match = fmt.Sprintf("%s %s == %v", acc.Matcher, acc.Argument, acc.Expected)
+			}
+			acceptors[y] = fmt.Sprintf("%s -> %s", match, acc.State)
+		}
+		rows[x] = []string{
+			waiter.Name,
+			waiter.Operation,
+			strconv.Itoa(waiter.Delay),
+			strconv.Itoa(waiter.MaxAttempts),
+			strings.Join(acceptors, "\n"),
+		}
+	}
+	noResults(rows)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(headers)
+	table.SetAutoWrapText(false)
+	table.SetRowLine(true)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
+
+func listEndpoints(cmd *cobra.Command, args []string) error {
+	api, err := getAPI(args[0])
+	if err != nil {
+		return err
+	}
+	endpoints, err := api.GetEndpoints()
+	if err != nil {
+		return err
+	}
+	partitions := []string{}
+	if cliListEndpointsPartitionFilter != "" {
+		partitions = strings.Split(cliListEndpointsPartitionFilter, ",")
+	}
+	headers := []string{"Partition", "Region", "Hostname", "Variants", "Credential Scope"}
+	rows := [][]string{}
+	for _, endpoint := range endpoints {
+		if !inStrings(endpoint.Partition, partitions) {
+			continue
+		}
+		variants := append([]string{}, endpoint.Variants...)
+		if endpoint.Global {
+			variants = append(variants, "global")
+		}
+		if endpoint.Deprecated {
+			variants = append(variants, "deprecated")
+		}
+		scope := []string{}
+		if endpoint.CredentialScopeRegion != "" {
+			scope = append(scope, "region="+endpoint.CredentialScopeRegion)
+		}
+		if endpoint.CredentialScopeService != "" {
+			scope = append(scope, "service="+endpoint.CredentialScopeService)
+		}
+		rows = append(rows, []string{
+			endpoint.Partition,
+			endpoint.Region,
+			endpoint.Hostname,
+			strings.Join(variants, ", "),
+			strings.Join(scope, ", "),
+		})
+	}
+	noResults(rows)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(headers)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
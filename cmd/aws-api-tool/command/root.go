@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -30,6 +31,10 @@ var (
 	debug            bool
 	defaultCachePath string
 	cachePath        string
+	modelsDir        string
+	modelsArchive    string
+	sdkRepo          string
+	cliWorkers       int
 )
 
 var rootCmd = &cobra.Command{
@@ -49,6 +54,23 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(
 		&cachePath, "cache-path", defaultCachePath, "Path to cache directory root",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&modelsDir, "models-dir", "", "Path to a local directory containing "+
+			"aws-sdk-go API models (models/apis/<alias>/<version>/api-2.json). "+
+			"When set, the aws-sdk-go repository is not cloned into the cache.",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&modelsArchive, "models-archive", "", "Path to a tar.gz or zip archive "+
+			"of the aws-sdk-go repository (e.g. a release tarball) to read API "+
+			"models from. When set, the aws-sdk-go repository is not cloned "+
+			"into the cache.",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&sdkRepo, "sdk-repo-url", sdkRepoURL, "URL of the aws-sdk-go git repository to clone into the cache.",
+	)
+	rootCmd.PersistentFlags().IntVar(
+		&cliWorkers, "workers", runtime.NumCPU(), "Maximum number of API models to load concurrently.",
+	)
 	rootCmd.PersistentFlags().BoolVar(
 		&debug, "debug", false, "Enable or disable debug mode",
 	)
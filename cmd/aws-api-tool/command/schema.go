@@ -7,14 +7,23 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
+	oai "github.com/getkin/kin-openapi/openapi3"
 	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
+
+	"github.com/jaypipes/aws-api-tools/pkg/apimodel"
 )
 
 var (
 	cliOutputFormat string
+	cliRPCStyle     string
+	cliSpecVersion  string
+	cliDocFormat    string
 )
 
 // schemaCmd shows a schema document for an AWS API service
@@ -29,6 +38,22 @@ func init() {
 	schemaCmd.PersistentFlags().StringVarP(
 		&cliOutputFormat, "format", "f", "yaml", "Output format for schema ('yaml' or 'json').",
 	)
+	schemaCmd.PersistentFlags().StringVar(
+		&cliRPCStyle, "rpc-style", apimodel.RPCStyleFragment,
+		"How to represent the operations of json and query protocol APIs ('"+
+			strings.Join(apimodel.RPCStyles, "', '")+"').",
+	)
+	schemaCmd.PersistentFlags().StringVar(
+		&cliSpecVersion, "spec-version", apimodel.SpecVersion30,
+		"OpenAPI specification version of the schema ('"+
+			strings.Join(apimodel.SpecVersions, "', '")+"').",
+	)
+	schemaCmd.PersistentFlags().StringVar(
+		&cliDocFormat, "doc-format", apimodel.DocFormatMarkdown,
+		"Format of the descriptions in the schema ('"+
+			strings.Join(apimodel.DocFormats, "', '")+"').",
+	)
+	addAPIVersionFlag(schemaCmd)
 	rootCmd.AddCommand(schemaCmd)
 }
 
@@ -37,18 +62,57 @@ func apiSchema(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	json, err := api.Schema().MarshalJSON()
+	opts := apimodel.DefaultSchemaOptions()
+	opts.RPCStyle = cliRPCStyle
+	opts.DocFormat = cliDocFormat
+	swagger, err := api.SchemaWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	var doc []byte
+	if cliSpecVersion == apimodel.SpecVersion30 {
+		doc, err = swagger.MarshalJSON()
+	} else {
+		doc, err = convertSchema(swagger, cliSpecVersion)
+	}
 	if err != nil {
 		return err
 	}
 	if cliOutputFormat == "yaml" {
-		yamlStr, err := yaml.JSONToYAML(json)
+		yamlStr, err := yaml.JSONToYAML(doc)
 		if err != nil {
 			return err
 		}
 		fmt.Printf(string(yamlStr))
 	} else {
-		fmt.Println(string(json))
+		fmt.Println(string(doc))
 	}
 	return nil
 }
+
+// convertSchema returns the JSON of the supplied OpenAPI 3.0 schema converted
+// to another specification version. The constructs that cannot be
+// represented in that version are reported on stderr, grouped by construct.
+func convertSchema(swagger *oai.Swagger, specVersion string) ([]byte, error) {
+	doc, issues, err := apimodel.ConvertSchema(swagger, specVersion)
+	if err != nil {
+		return nil, err
+	}
+	groups := []string{}
+	groupIssues := map[string][]*apimodel.ConversionIssue{}
+	for _, issue := range issues {
+		group := issue.Construct + " (" + issue.Resolution + ")"
+		if _, found := groupIssues[group]; !found {
+			groups = append(groups, group)
+		}
+		groupIssues[group] = append(groupIssues[group], issue)
+	}
+	for _, group := range groups {
+		issues := groupIssues[group]
+		fmt.Fprintf(
+			os.Stderr, "WARNING: %s cannot represent %s at %d location(s), e.g. %s\n",
+			apimodel.SpecVersionName(specVersion), group, len(issues), issues[0].Path,
+		)
+	}
+	return json.Marshal(doc)
+}
@@ -18,6 +18,7 @@ Date: %s
 Build: %s
 Version: %s
 Git Hash: %s
+AWS SDK: %s
 `
 
 // versionCmd represents the version command
@@ -26,10 +27,27 @@ var versionCmd = &cobra.Command{
 	Short: "Display the version of " + appName,
 	Run: func(cmd *cobra.Command, args []string) {
 		goVersion := fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH)
-		fmt.Printf(debugHeader, buildDate, goVersion, version, buildHash)
+		fmt.Printf(debugHeader, buildDate, goVersion, version, buildHash, cachedSDKRef())
 	},
 }
 
+// cachedSDKRef returns a description of the aws-sdk-go commit the cached API
+// models come from
+func cachedSDKRef() string {
+	if !sdkRepoCloned() {
+		return "(not cached)"
+	}
+	commit, err := git(sdkClonePath(), "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Sprintf("(unknown: %v)", err)
+	}
+	state, err := readCacheState()
+	if err == nil && state != nil && state.PinnedRef != "" {
+		return fmt.Sprintf("%s (pinned to %s)", commit, state.PinnedRef)
+	}
+	return commit
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
 }
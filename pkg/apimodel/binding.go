@@ -0,0 +1,188 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"fmt"
+	"sort"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// Members of an operation's input and output shapes with a location are bound
+// to a part of the HTTP request or response other than the body
+const (
+	locationURI         = "uri"
+	locationQueryString = "querystring"
+	locationHeader      = "header"
+	locationHeaders     = "headers"
+	locationStatusCode  = "statusCode"
+)
+
+// methodsWithoutBody are the HTTP methods whose requests should not carry a
+// body unless the operation's input requires one
+var methodsWithoutBody = []string{"GET", "HEAD", "DELETE"}
+
+// memberLocation returns the location a member is bound to, or the empty
+// string if the member is serialized in the body
+func memberLocation(ref *shapeRefSpec) string {
+	if ref.Location == nil {
+		return ""
+	}
+	return *ref.Location
+}
+
+// wireName returns the name of a member in the HTTP request or response
+func wireName(memberName string, ref *shapeRefSpec) string {
+	if ref.LocationName != "" {
+		return ref.LocationName
+	}
+	return memberName
+}
+
+// sortedMemberNames returns the names of a shape's members in sorted order
+func sortedMemberNames(ss *shapeSpec) []string {
+	res := make([]string, 0, len(ss.Members))
+	for memberName := range ss.Members {
+		res = append(res, memberName)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func componentSchemaRef(shapeName string) *oai.SchemaRef {
+	return oai.NewSchemaRef("#/components/schemas/"+shapeName, nil)
+}
+
+// bindingParameters returns the OpenAPI parameters for the members of an
+// operation's input shape that are bound to the request URI, query string or
+// headers, along with a map, keyed by header prefix, of the names of members
+// bound to prefixed headers, which OpenAPI cannot describe as parameters
+func bindingParameters(ss *shapeSpec) (oai.Parameters, map[string]string) {
+	params := oai.NewParameters()
+	prefixHeaders := map[string]string{}
+	for _, memberName := range sortedMemberNames(ss) {
+		ref := ss.Members[memberName]
+		name := wireName(memberName, ref)
+		var param *oai.Parameter
+		switch memberLocation(ref) {
+		case locationURI:
+			param = oai.NewPathParameter(name)
+		case locationQueryString:
+			param = oai.NewQueryParameter(name)
+			param.Required = inStrings(memberName, ss.Required)
+		case locationHeader:
+			param = oai.NewHeaderParameter(name)
+			param.Required = inStrings(memberName, ss.Required)
+		case locationHeaders:
+			prefixHeaders[ref.LocationName] = memberName
+			continue
+		default:
+			continue
+		}
+		param.Schema = componentSchemaRef(*ref.ShapeName)
+		param.Deprecated = ref.Deprecated
+		params = append(params, &oai.ParameterRef{Value: param})
+	}
+	return params, prefixHeaders
+}
+
+// bindingHeaders returns the OpenAPI response headers for the members of an
+// operation's output shape that are bound to the response headers, along with
+// a map, keyed by header prefix, of the names of members bound to prefixed
+// headers
+func bindingHeaders(ss *shapeSpec) (map[string]*oai.HeaderRef, map[string]string) {
+	headers := map[string]*oai.HeaderRef{}
+	prefixHeaders := map[string]string{}
+	for _, memberName := range sortedMemberNames(ss) {
+		ref := ss.Members[memberName]
+		switch memberLocation(ref) {
+		case locationHeader:
+			headers[wireName(memberName, ref)] = &oai.HeaderRef{
+				Value: &oai.Header{
+					Schema:     componentSchemaRef(*ref.ShapeName),
+					Deprecated: ref.Deprecated,
+				},
+			}
+		case locationHeaders:
+			prefixHeaders[ref.LocationName] = memberName
+		}
+	}
+	return headers, prefixHeaders
+}
+
+// bodyContent returns the OpenAPI content describing the HTTP body of an
+// operation's input or output shape, or nil if there is no body.
+//
+// If the shape has a payload member, the body is the payload member's shape.
+// If none of the shape's members are bound to the URI, query string or
+// headers, the body is the entire shape. Otherwise the body is an object
+// containing only the shape's unbound members, and there is no body at all
+// when every member is bound. When omitEmpty is true, a shape without any
+// members has no body either. Bodies other than raw payloads are serialized
+// in the supplied format.
+func bodyContent(
+	shapeName string,
+	ss *shapeSpec,
+	apiSpec *apiSpec,
+	format *bodyFormat,
+	omitEmpty bool,
+) (oai.Content, error) {
+	if ss.Payload != "" {
+		ref, found := ss.Members[ss.Payload]
+		if !found {
+			return nil, fmt.Errorf(
+				"expected to find payload member %s in shape %s", ss.Payload, shapeName,
+			)
+		}
+		payloadShape, found := apiSpec.Shapes[*ref.ShapeName]
+		if !found {
+			return nil, fmt.Errorf("expected to find payload shape %s", *ref.ShapeName)
+		}
+		schemaRef := componentSchemaRef(*ref.ShapeName)
+		switch payloadShape.Type {
+		case "blob", "string":
+			content := oai.NewContent()
+			content[mediaTypeOctetStream] = oai.NewMediaType().WithSchemaRef(schemaRef)
+			return content, nil
+		}
+		if apiSpec.Metadata.isXML() {
+			// The payload member, not the shape containing it, is the root
+			// element of an XML body
+			format = &bodyFormat{
+				mediaType: format.mediaType,
+				root:      apiSpec.Metadata.rootXML(ref, payloadShape),
+			}
+		}
+		return format.newContent(schemaRef), nil
+	}
+	unbound := []string{}
+	for _, memberName := range sortedMemberNames(ss) {
+		switch memberLocation(ss.Members[memberName]) {
+		case "":
+			unbound = append(unbound, memberName)
+		}
+	}
+	if len(unbound) == len(ss.Members) {
+		if omitEmpty && len(ss.Members) == 0 {
+			return nil, nil
+		}
+		return format.newContent(componentSchemaRef(shapeName)), nil
+	}
+	if len(unbound) == 0 {
+		return nil, nil
+	}
+	schema := oai.NewObjectSchema()
+	for _, memberName := range unbound {
+		ref := ss.Members[memberName]
+		schema.WithPropertyRef(wireName(memberName, ref), componentSchemaRef(*ref.ShapeName))
+		if inStrings(memberName, ss.Required) {
+			schema.Required = append(schema.Required, wireName(memberName, ref))
+		}
+	}
+	return format.newContent(oai.NewSchemaRef("", schema)), nil
+}
@@ -0,0 +1,228 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+const lambdaModel = `{
+  "metadata": {
+    "apiVersion": "2015-03-31",
+    "endpointPrefix": "lambda",
+    "protocol": "rest-json",
+    "serviceFullName": "AWS Lambda",
+    "serviceId": "Lambda",
+    "signatureVersion": "v4"
+  },
+  "operations": {
+    "Invoke": {
+      "name": "Invoke",
+      "http": {"method": "POST", "requestUri": "/2015-03-31/functions/{FunctionName}/invocations"},
+      "input": {"shape": "InvocationRequest"},
+      "output": {"shape": "InvocationResponse"}
+    },
+    "CreateAlias": {
+      "name": "CreateAlias",
+      "http": {"method": "POST", "requestUri": "/2015-03-31/functions/{FunctionName}/aliases", "responseCode": 201},
+      "input": {"shape": "CreateAliasRequest"},
+      "output": {"shape": "AliasConfiguration"}
+    },
+    "PutFunctionConcurrency": {
+      "name": "PutFunctionConcurrency",
+      "http": {"method": "PUT", "requestUri": "/2017-10-31/functions/{FunctionName}/concurrency"},
+      "input": {"shape": "PutFunctionConcurrencyRequest"}
+    },
+    "DeleteFunction": {
+      "name": "DeleteFunction",
+      "http": {"method": "DELETE", "requestUri": "/2015-03-31/functions/{FunctionName}", "responseCode": 204},
+      "input": {"shape": "DeleteFunctionRequest"}
+    }
+  },
+  "shapes": {
+    "InvocationRequest": {
+      "type": "structure",
+      "required": ["FunctionName"],
+      "members": {
+        "FunctionName": {"shape": "String", "location": "uri", "locationName": "FunctionName"},
+        "InvocationType": {"shape": "String", "location": "header", "locationName": "X-Amz-Invocation-Type"},
+        "Qualifier": {"shape": "String", "location": "querystring", "locationName": "Qualifier"},
+        "Payload": {"shape": "Blob"}
+      },
+      "payload": "Payload"
+    },
+    "InvocationResponse": {
+      "type": "structure",
+      "members": {
+        "StatusCode": {"shape": "Integer", "location": "statusCode"},
+        "FunctionError": {"shape": "String", "location": "header", "locationName": "X-Amz-Function-Error"},
+        "Metadata": {"shape": "MetadataMap", "location": "headers", "locationName": "x-amz-meta-"},
+        "Payload": {"shape": "Blob"}
+      },
+      "payload": "Payload"
+    },
+    "CreateAliasRequest": {
+      "type": "structure",
+      "required": ["FunctionName", "Name", "ClientToken"],
+      "members": {
+        "FunctionName": {"shape": "String", "location": "uri", "locationName": "FunctionName"},
+        "ClientToken": {"shape": "String", "location": "header", "locationName": "X-Amz-Client-Token"},
+        "Metadata": {"shape": "MetadataMap", "location": "headers", "locationName": "x-amz-meta-"},
+        "Name": {"shape": "String"},
+        "Description": {"shape": "String", "locationName": "description"}
+      }
+    },
+    "AliasConfiguration": {
+      "type": "structure",
+      "members": {
+        "AliasArn": {"shape": "String"},
+        "Name": {"shape": "String"}
+      }
+    },
+    "PutFunctionConcurrencyRequest": {
+      "type": "structure",
+      "required": ["FunctionName", "Concurrency"],
+      "members": {
+        "FunctionName": {"shape": "String", "location": "uri", "locationName": "FunctionName"},
+        "Concurrency": {"shape": "Concurrency"}
+      },
+      "payload": "Concurrency"
+    },
+    "Concurrency": {
+      "type": "structure",
+      "members": {"ReservedConcurrentExecutions": {"shape": "Integer"}}
+    },
+    "DeleteFunctionRequest": {
+      "type": "structure",
+      "required": ["FunctionName"],
+      "members": {
+        "FunctionName": {"shape": "String", "location": "uri", "locationName": "FunctionName"},
+        "Qualifier": {"shape": "String", "location": "querystring", "locationName": "Qualifier"}
+      }
+    },
+    "MetadataMap": {
+      "type": "map",
+      "key": {"shape": "String"},
+      "value": {"shape": "String"}
+    },
+    "Blob": {"type": "blob"},
+    "Integer": {"type": "integer"},
+    "String": {"type": "string"}
+  }
+}`
+
+func TestBindingParameters(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "lambda", lambdaModel))
+	tests := []struct {
+		operationID string
+		in          string
+		name        string
+		required    bool
+	}{
+		{"Invoke", oai.ParameterInPath, "FunctionName", true},
+		{"Invoke", oai.ParameterInHeader, "X-Amz-Invocation-Type", false},
+		{"Invoke", oai.ParameterInQuery, "Qualifier", false},
+		{"CreateAlias", oai.ParameterInHeader, "X-Amz-Client-Token", true},
+		{"DeleteFunction", oai.ParameterInQuery, "Qualifier", false},
+	}
+	for _, test := range tests {
+		_, op := findOperation(t, swagger, test.operationID)
+		param := findParameter(t, op, test.in, test.name)
+		if param.Required != test.required {
+			t.Errorf("%s: expected %s parameter %s required to be %v", test.operationID, test.in, test.name, test.required)
+		}
+		if param.Schema == nil || param.Schema.Ref != "#/components/schemas/String" {
+			t.Errorf("%s: expected %s parameter %s to refer to the String schema, got %v", test.operationID, test.in, test.name, param.Schema)
+		}
+	}
+
+	// Members bound to prefixed headers cannot be described as parameters
+	_, op := findOperation(t, swagger, "CreateAlias")
+	if len(op.Parameters) != 2 {
+		t.Errorf("CreateAlias: expected 2 parameters, got %d", len(op.Parameters))
+	}
+	want := map[string]string{"x-amz-meta-": "Metadata"}
+	if got := op.Extensions["x-aws-prefix-headers"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateAlias: expected x-aws-prefix-headers %v, got %v", want, got)
+	}
+}
+
+func TestBindingHeaders(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "lambda", lambdaModel))
+	_, op := findOperation(t, swagger, "Invoke")
+	resp := op.Responses["200"].Value
+	if len(resp.Headers) != 1 || resp.Headers["X-Amz-Function-Error"] == nil {
+		t.Fatalf("expected the response header X-Amz-Function-Error, got %v", resp.Headers)
+	}
+	if ref := resp.Headers["X-Amz-Function-Error"].Value.Schema.Ref; ref != "#/components/schemas/String" {
+		t.Errorf("expected response header X-Amz-Function-Error to refer to the String schema, got %s", ref)
+	}
+	want := map[string]string{"x-amz-meta-": "Metadata"}
+	if got := resp.Extensions["x-aws-prefix-headers"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected response x-aws-prefix-headers %v, got %v", want, got)
+	}
+}
+
+func TestBodyContent(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "lambda", lambdaModel))
+
+	// Blob payloads are raw bodies
+	_, op := findOperation(t, swagger, "Invoke")
+	for name, content := range map[string]oai.Content{
+		"request":  op.RequestBody.Value.Content,
+		"response": op.Responses["200"].Value.Content,
+	} {
+		mt := content.Get(mediaTypeOctetStream)
+		if len(content) != 1 || mt == nil {
+			t.Errorf("Invoke: expected a %s %s body, got %v", mediaTypeOctetStream, name, content)
+			continue
+		}
+		if ref := mt.Schema.Ref; ref != "#/components/schemas/Blob" {
+			t.Errorf("Invoke: expected the %s body to refer to the Blob schema, got %s", name, ref)
+		}
+	}
+
+	// Structure payloads are the entire body
+	_, op = findOperation(t, swagger, "PutFunctionConcurrency")
+	mt := op.RequestBody.Value.Content.Get(mediaTypeJSON)
+	if mt == nil || mt.Schema.Ref != "#/components/schemas/Concurrency" {
+		t.Errorf("PutFunctionConcurrency: expected a JSON body referring to the Concurrency schema, got %v", op.RequestBody.Value.Content)
+	}
+
+	// Without a payload, the body is an object of the unbound members
+	_, op = findOperation(t, swagger, "CreateAlias")
+	mt = op.RequestBody.Value.Content.Get(mediaTypeJSON)
+	if mt == nil {
+		t.Fatalf("CreateAlias: expected a JSON body, got %v", op.RequestBody.Value.Content)
+	}
+	body := mt.Schema.Value
+	if body == nil || len(body.Properties) != 2 {
+		t.Fatalf("CreateAlias: expected a body of 2 properties, got %v", mt.Schema)
+	}
+	for _, name := range []string{"Name", "description"} {
+		if prop := body.Properties[name]; prop == nil || prop.Ref != "#/components/schemas/String" {
+			t.Errorf("CreateAlias: expected body property %s referring to the String schema, got %v", name, prop)
+		}
+	}
+	if !reflect.DeepEqual(body.Required, []string{"Name"}) {
+		t.Errorf("CreateAlias: expected the body property Name to be required, got %v", body.Required)
+	}
+	// ... and the body is the entire shape when no member is bound
+	mt = op.Responses["201"].Value.Content.Get(mediaTypeJSON)
+	if mt == nil || mt.Schema.Ref != "#/components/schemas/AliasConfiguration" {
+		t.Errorf("CreateAlias: expected a response body referring to the AliasConfiguration schema, got %v", op.Responses["201"].Value.Content)
+	}
+
+	// There is no body when every member is bound
+	_, op = findOperation(t, swagger, "DeleteFunction")
+	if op.RequestBody != nil {
+		t.Errorf("DeleteFunction: expected no request body, got %v", op.RequestBody.Value.Content)
+	}
+}
@@ -0,0 +1,174 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"regexp"
+	"strings"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+	"github.com/mattn/godown"
+)
+
+// Formats of the documentation in the OpenAPI schema. The API models
+// document services, operations, shapes and members with HTML.
+const (
+	// DocFormatHTML keeps the documentation as HTML
+	DocFormatHTML = "html"
+	// DocFormatMarkdown converts the documentation to Markdown, which is what
+	// OpenAPI descriptions are supposed to contain
+	DocFormatMarkdown = "markdown"
+	// DocFormatNone omits the documentation
+	DocFormatNone = "none"
+)
+
+// DocFormats are the supported documentation formats
+var DocFormats = []string{DocFormatHTML, DocFormatMarkdown, DocFormatNone}
+
+var (
+	// fullNameRegexp matches the <fullname> element service documentation
+	// starts with, which repeats the service's full name
+	fullNameRegexp = regexp.MustCompile(`(?s)<fullname>.*?</fullname>`)
+	// emptyLinkRegexp matches the Markdown links converted from <a> elements
+	// without an href, which the API models use to refer to other operations
+	// and shapes, e.g. "<a>CreateTopic</a>"
+	emptyLinkRegexp = regexp.MustCompile(`\[([^\]]*)\]\(\)`)
+	// blankLinesRegexp matches runs of blank lines
+	blankLinesRegexp = regexp.MustCompile(`\n{3,}`)
+)
+
+// formatDoc returns the supplied HTML documentation in the supplied format
+func formatDoc(doc string, format string) string {
+	switch format {
+	case DocFormatNone:
+		return ""
+	case DocFormatHTML:
+		return doc
+	}
+	doc = fullNameRegexp.ReplaceAllString(doc, "")
+	if doc == "" {
+		return ""
+	}
+	var b strings.Builder
+	if err := godown.Convert(&b, strings.NewReader(doc), nil); err != nil {
+		return doc
+	}
+	md := emptyLinkRegexp.ReplaceAllString(b.String(), "$1")
+	md = blankLinesRegexp.ReplaceAllString(md, "\n\n")
+	return strings.TrimSpace(md)
+}
+
+// shapeDoc returns the documentation of a shape in the supplied format
+func (api *API) shapeDoc(shapeName string, format string) string {
+	doc, found := api.docSpec.Shapes[shapeName]
+	if !found || doc.Base == nil {
+		return ""
+	}
+	return formatDoc(*doc.Base, format)
+}
+
+// memberDoc returns the documentation of a structure's member in the
+// supplied format. The documentation of members is found in the refs of the
+// member's target shape, keyed by "<structure>$<member>".
+func (api *API) memberDoc(
+	shapeName string,
+	memberName string,
+	ref *shapeRefSpec,
+	format string,
+) string {
+	if ref == nil || ref.ShapeName == nil {
+		return ""
+	}
+	doc, found := api.docSpec.Shapes[*ref.ShapeName]
+	if !found {
+		return ""
+	}
+	return formatDoc(doc.Refs[shapeName+"$"+memberName], format)
+}
+
+// withDescription returns a schema reference with the supplied description.
+// A referenced component schema is wrapped in an allOf, because OpenAPI 3.0
+// ignores the siblings of $ref.
+func withDescription(schemaRef *oai.SchemaRef, description string) *oai.SchemaRef {
+	if description == "" {
+		return schemaRef
+	}
+	if schemaRef.Ref != "" {
+		schemaRef = oai.NewSchemaRef("", &oai.Schema{
+			AllOf: []*oai.SchemaRef{schemaRef},
+		})
+	}
+	schemaRef.Value.Description = description
+	return schemaRef
+}
+
+// addSchemaDocs describes each component schema with the documentation of
+// its shape and each property of a structure's schema with the documentation
+// of its member
+func (api *API) addSchemaDocs(schemas map[string]*oai.SchemaRef, format string) {
+	for shapeName, ss := range api.apiSpec.Shapes {
+		schemaRef, found := schemas[shapeName]
+		if !found || schemaRef.Value == nil {
+			continue
+		}
+		schema := schemaRef.Value
+		schema.Description = api.shapeDoc(shapeName, format)
+		for memberName, ref := range ss.Members {
+			prop, found := schema.Properties[memberName]
+			if !found {
+				continue
+			}
+			schema.Properties[memberName] = withDescription(
+				prop, api.memberDoc(shapeName, memberName, ref, format),
+			)
+		}
+	}
+}
+
+// parameterLocations maps the locations of members bound to the request URI,
+// query string or headers to the location of their OpenAPI parameters
+var parameterLocations = map[string]string{
+	locationURI:         oai.ParameterInPath,
+	locationQueryString: oai.ParameterInQuery,
+	locationHeader:      oai.ParameterInHeader,
+}
+
+// addParameterDocs describes the operation's parameters and response headers
+// with the documentation of the members of the input and output shapes they
+// are bound to
+func (api *API) addParameterDocs(op *oai.Operation, opSpec *opSpec, format string) {
+	if opSpec.Input != nil && opSpec.Input.ShapeName != nil {
+		inShapeName := *opSpec.Input.ShapeName
+		inShape := api.apiSpec.Shapes[inShapeName]
+		for _, paramRef := range op.Parameters {
+			param := paramRef.Value
+			for memberName, ref := range inShape.Members {
+				if param.Name == wireName(memberName, ref) &&
+					param.In == parameterLocations[memberLocation(ref)] {
+					param.Description = api.memberDoc(inShapeName, memberName, ref, format)
+				}
+			}
+		}
+	}
+	if opSpec.Output != nil && opSpec.Output.ShapeName != nil {
+		outShapeName := *opSpec.Output.ShapeName
+		outShape := api.apiSpec.Shapes[outShapeName]
+		for _, respRef := range op.Responses {
+			if respRef.Ref != "" {
+				continue
+			}
+			for memberName, ref := range outShape.Members {
+				if memberLocation(ref) != locationHeader {
+					continue
+				}
+				if header, found := respRef.Value.Headers[wireName(memberName, ref)]; found {
+					header.Value.Description = api.memberDoc(outShapeName, memberName, ref, format)
+				}
+			}
+		}
+	}
+}
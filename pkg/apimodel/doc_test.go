@@ -0,0 +1,135 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"testing"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const dynamodbDocs = `{
+  "version": "2.0",
+  "service": "<fullname>Amazon DynamoDB</fullname> <p>Amazon DynamoDB is a <b>fully managed</b> NoSQL database service.</p>",
+  "operations": {
+    "DescribeTable": "<p>Returns information about the table. See <a>ListTables</a>.</p>"
+  },
+  "shapes": {
+    "String": {
+      "base": null,
+      "refs": {
+        "DescribeTableInput$TableName": "<p>The name of the table to describe.</p>"
+      }
+    },
+    "TableDescription": {
+      "base": "<p>Represents the properties of a table.</p>",
+      "refs": {
+        "DescribeTableOutput$Table": "<p>The properties of the <code>Table</code>.</p>"
+      }
+    }
+  }
+}`
+
+func TestFormatDoc(t *testing.T) {
+	tests := []struct {
+		doc    string
+		format string
+		want   string
+	}{
+		{"<p>A <b>bold</b> claim.</p>", DocFormatMarkdown, "A **bold** claim."},
+		{"<p>Call <code>GetItem</code>.</p>", DocFormatMarkdown, "Call `GetItem`."},
+		// Links without an href refer to other operations and shapes
+		{"<p>See <a>CreateTable</a>.</p>", DocFormatMarkdown, "See CreateTable."},
+		{`<p>See <a href="https://aws.amazon.com/">AWS</a>.</p>`, DocFormatMarkdown, "See [AWS](https://aws.amazon.com/)."},
+		{"<p>One.</p><p/><p/><p>Two.</p>", DocFormatMarkdown, "One.\n\nTwo."},
+		{"<fullname>Amazon DynamoDB</fullname><p>Fast.</p>", DocFormatMarkdown, "Fast."},
+		{"<fullname>Amazon DynamoDB</fullname>", DocFormatMarkdown, ""},
+		{"", DocFormatMarkdown, ""},
+		// HTML is kept as is, <fullname> included
+		{"<fullname>Amazon DynamoDB</fullname><p>Fast.</p>", DocFormatHTML, "<fullname>Amazon DynamoDB</fullname><p>Fast.</p>"},
+		{"<p>Fast.</p>", DocFormatNone, ""},
+	}
+	for _, test := range tests {
+		if got := formatDoc(test.doc, test.format); got != test.want {
+			t.Errorf("%q as %s: expected %q, got %q", test.doc, test.format, test.want, got)
+		}
+	}
+}
+
+func TestSchemaDocs(t *testing.T) {
+	files := map[string]string{
+		model.ModelFile: dynamodbTablesModel,
+		model.DocsFile:  dynamodbDocs,
+	}
+	swagger := testSchema(t, newTestAPIFromFiles(t, "dynamodb", files))
+	if got, want := swagger.Info.Description, "Amazon DynamoDB is a **fully managed** NoSQL database service."; got != want {
+		t.Errorf("expected the API description %q, got %q", want, got)
+	}
+	_, op := findOperation(t, swagger, "DescribeTable")
+	if got, want := op.Description, "Returns information about the table. See ListTables."; got != want {
+		t.Errorf("expected the DescribeTable description %q, got %q", want, got)
+	}
+	schemas := swagger.Components.Schemas
+	if got, want := schemas["TableDescription"].Value.Description, "Represents the properties of a table."; got != want {
+		t.Errorf("expected the TableDescription description %q, got %q", want, got)
+	}
+
+	// Member documentation is found in the refs of the member's shape, keyed
+	// by "<structure>$<member>". A documented property referring to a
+	// component schema is wrapped in an allOf, because OpenAPI ignores the
+	// siblings of $ref.
+	tests := []struct {
+		shapeName   string
+		memberName  string
+		ref         string
+		description string
+	}{
+		{"DescribeTableInput", "TableName", "#/components/schemas/String", "The name of the table to describe."},
+		{"DescribeTableOutput", "Table", "#/components/schemas/TableDescription", "The properties of the `Table`."},
+	}
+	for _, test := range tests {
+		prop := schemas[test.shapeName].Value.Properties[test.memberName]
+		if prop.Ref != "" || len(prop.Value.AllOf) != 1 || prop.Value.AllOf[0].Ref != test.ref {
+			t.Errorf("%s.%s: expected an allOf of %s, got %v", test.shapeName, test.memberName, test.ref, prop)
+			continue
+		}
+		if prop.Value.Description != test.description {
+			t.Errorf("%s.%s: expected the description %q, got %q", test.shapeName, test.memberName, test.description, prop.Value.Description)
+		}
+	}
+	// Undocumented properties still refer to their component schema directly
+	if prop := schemas["TableDescription"].Value.Properties["TableName"]; prop.Ref != "#/components/schemas/String" {
+		t.Errorf("TableDescription.TableName: expected a $ref to String, got %v", prop)
+	}
+
+	// Without documentation, nothing is described and nothing is wrapped
+	opts := DefaultSchemaOptions()
+	opts.DocFormat = DocFormatNone
+	swagger, err := newTestAPIFromFiles(t, "dynamodb", files).SchemaWithOptions(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if swagger.Info.Description != "" {
+		t.Errorf("expected no API description, got %q", swagger.Info.Description)
+	}
+	_, op = findOperation(t, swagger, "DescribeTable")
+	if op.Description != "" {
+		t.Errorf("expected no DescribeTable description, got %q", op.Description)
+	}
+	schemas = swagger.Components.Schemas
+	if desc := schemas["TableDescription"].Value.Description; desc != "" {
+		t.Errorf("expected no TableDescription description, got %q", desc)
+	}
+	if prop := schemas["DescribeTableInput"].Value.Properties["TableName"]; prop.Ref != "#/components/schemas/String" {
+		t.Errorf("DescribeTableInput.TableName: expected a $ref to String, got %v", prop)
+	}
+
+	opts.DocFormat = "pdf"
+	if _, err := newTestAPIFromFiles(t, "dynamodb", files).SchemaWithOptions(opts); err == nil {
+		t.Errorf("expected an error for an unknown doc format")
+	}
+}
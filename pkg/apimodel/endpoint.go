@@ -0,0 +1,395 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// Endpoint variant tags
+const (
+	EndpointVariantFIPS      = "fips"
+	EndpointVariantDualStack = "dualstack"
+)
+
+// defaultServerRegion is the default value of the region server variable
+// when the partition has the region
+const defaultServerRegion = "us-east-1"
+
+// localRegion is the pseudo-region of endpoints served by a local server
+// instead of AWS, e.g. DynamoDB Local
+const localRegion = "local"
+
+type credentialScopeSpec struct {
+	Region  string `json:"region"`
+	Service string `json:"service"`
+}
+
+type endpointVariantSpec struct {
+	Hostname  string   `json:"hostname"`
+	DNSSuffix string   `json:"dnsSuffix"`
+	Tags      []string `json:"tags"`
+}
+
+// partitionEndpointSpec describes an endpoint, or the defaults for the
+// endpoints of a partition or service, in endpoints.json
+type partitionEndpointSpec struct {
+	Hostname          string                `json:"hostname"`
+	DNSSuffix         string                `json:"dnsSuffix"`
+	Protocols         []string              `json:"protocols"`
+	CredentialScope   credentialScopeSpec   `json:"credentialScope"`
+	SignatureVersions []string              `json:"signatureVersions"`
+	Variants          []endpointVariantSpec `json:"variants"`
+	Deprecated        bool                  `json:"deprecated"`
+}
+
+// merge returns the endpoint spec with the non-empty fields of other
+// overriding its own. Variants and deprecation are taken from other only.
+func (e partitionEndpointSpec) merge(other partitionEndpointSpec) partitionEndpointSpec {
+	if other.Hostname != "" {
+		e.Hostname = other.Hostname
+	}
+	if other.DNSSuffix != "" {
+		e.DNSSuffix = other.DNSSuffix
+	}
+	if len(other.Protocols) > 0 {
+		e.Protocols = other.Protocols
+	}
+	if other.CredentialScope.Region != "" {
+		e.CredentialScope.Region = other.CredentialScope.Region
+	}
+	if other.CredentialScope.Service != "" {
+		e.CredentialScope.Service = other.CredentialScope.Service
+	}
+	if len(other.SignatureVersions) > 0 {
+		e.SignatureVersions = other.SignatureVersions
+	}
+	e.Variants = other.Variants
+	e.Deprecated = other.Deprecated
+	return e
+}
+
+// variant returns the default variant of the endpoint spec with the supplied
+// tags, or nil if there is no such variant
+func (e partitionEndpointSpec) variant(tags []string) *endpointVariantSpec {
+	for x, v := range e.Variants {
+		if sameTags(v.Tags, tags) {
+			return &e.Variants[x]
+		}
+	}
+	return nil
+}
+
+type partitionServiceSpec struct {
+	Defaults          partitionEndpointSpec            `json:"defaults"`
+	Endpoints         map[string]partitionEndpointSpec `json:"endpoints"`
+	IsRegionalized    *bool                            `json:"isRegionalized"`
+	PartitionEndpoint string                           `json:"partitionEndpoint"`
+}
+
+type regionSpec struct {
+	Description string `json:"description"`
+}
+
+type partitionSpec struct {
+	Partition     string                           `json:"partition"`
+	PartitionName string                           `json:"partitionName"`
+	DNSSuffix     string                           `json:"dnsSuffix"`
+	Defaults      partitionEndpointSpec            `json:"defaults"`
+	Regions       map[string]regionSpec            `json:"regions"`
+	Services      map[string]*partitionServiceSpec `json:"services"`
+}
+
+type endpointsSpec struct {
+	Partitions []*partitionSpec `json:"partitions"`
+}
+
+// parseEndpoints returns the partitions in the supplied endpoints.json
+// document
+func parseEndpoints(b []byte) ([]*partitionSpec, error) {
+	var spec endpointsSpec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode endpoints: %v", err)
+	}
+	return spec.Partitions, nil
+}
+
+// Endpoint describes where an API is served in one region of an AWS
+// partition
+type Endpoint struct {
+	// Partition is the ID of the partition, e.g. "aws" or "aws-cn"
+	Partition string
+	// PartitionName is the human-readable name of the partition, e.g. "AWS
+	// Standard"
+	PartitionName string
+	// Region is the region of the endpoint or, for global and legacy FIPS
+	// endpoints, the name of its pseudo-region, e.g. "aws-global" or
+	// "fips-us-east-1"
+	Region string
+	// Hostname is the host name requests to the endpoint are sent to
+	Hostname string
+	// Protocols are the URL schemes the endpoint supports
+	Protocols []string
+	// Variants contains the tags, e.g. "fips" or "dualstack", of an endpoint
+	// variant. It is empty for the standard endpoint of a region.
+	Variants []string
+	// CredentialScopeRegion is the region requests to the endpoint are
+	// signed for, if it differs from Region
+	CredentialScopeRegion string
+	// CredentialScopeService is the service name requests to the endpoint
+	// are signed for, if it differs from the API's signing name
+	CredentialScopeService string
+	// SignatureVersions are the signature versions the endpoint supports
+	SignatureVersions []string
+	// Global is true for the single endpoint of an API that is not
+	// regionalized
+	Global bool
+	// Deprecated is true if the endpoint should no longer be used
+	Deprecated bool
+}
+
+// Local returns true if the endpoint is not served by AWS but by a local
+// server, like the "local" pseudo-region of the DynamoDB API, whose host
+// name is "localhost:8000" for DynamoDB Local
+func (e *Endpoint) Local() bool {
+	if e.Region == localRegion {
+		return true
+	}
+	host := e.Hostname
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// URL returns the URL of the endpoint, preferring HTTPS
+func (e *Endpoint) URL() string {
+	scheme := "https"
+	if len(e.Protocols) > 0 && !inStrings(scheme, e.Protocols) {
+		scheme = e.Protocols[0]
+	}
+	return scheme + "://" + e.Hostname
+}
+
+// GetEndpoints returns the endpoints of the API in each AWS partition, found
+// by looking up the API's endpoint prefix in the endpoints file. The slice is
+// empty if the model source has no endpoints file or the API has no endpoint
+// prefix.
+func (a *API) GetEndpoints() ([]*Endpoint, error) {
+	service := a.apiSpec.Metadata.EndpointPrefix
+	if a.endpointsDoc == nil || service == "" {
+		return []*Endpoint{}, nil
+	}
+	partitions, err := parseEndpoints(a.endpointsDoc)
+	if err != nil {
+		return nil, err
+	}
+	res := []*Endpoint{}
+	for _, partition := range partitions {
+		res = append(res, partition.endpoints(service)...)
+	}
+	return res, nil
+}
+
+// endpoints returns the partition's endpoints for the service with the
+// supplied endpoint prefix, sorted by region, with each region's variants
+// following its standard endpoint
+func (p *partitionSpec) endpoints(service string) []*Endpoint {
+	svc, found := p.Services[service]
+	if !found {
+		return nil
+	}
+	regionalized := svc.IsRegionalized == nil || *svc.IsRegionalized
+	defaults := p.Defaults.merge(svc.Defaults)
+	regions := make([]string, 0, len(svc.Endpoints))
+	for region := range svc.Endpoints {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	res := []*Endpoint{}
+	for _, region := range regions {
+		spec := defaults.merge(svc.Endpoints[region])
+		endpoint := &Endpoint{
+			Partition:              p.Partition,
+			PartitionName:          p.PartitionName,
+			Region:                 region,
+			Hostname:               p.hostname(spec.Hostname, spec.DNSSuffix, service, region),
+			Protocols:              spec.Protocols,
+			CredentialScopeRegion:  spec.CredentialScope.Region,
+			CredentialScopeService: spec.CredentialScope.Service,
+			SignatureVersions:      spec.SignatureVersions,
+			Global:                 !regionalized && region == svc.PartitionEndpoint,
+			Deprecated:             spec.Deprecated,
+		}
+		// Older endpoint files describe FIPS endpoints as pseudo-regions,
+		// e.g. "fips-us-east-1" or "iam-fips", instead of variants
+		if strings.Contains(region, EndpointVariantFIPS) {
+			endpoint.Variants = []string{EndpointVariantFIPS}
+		}
+		res = append(res, endpoint)
+		for _, v := range spec.Variants {
+			variant := *endpoint
+			variant.Variants = v.Tags
+			variant.Hostname = p.hostname(
+				p.variantHostname(v, svc.Defaults),
+				p.variantDNSSuffix(v, svc.Defaults),
+				service, region,
+			)
+			res = append(res, &variant)
+		}
+	}
+	return res
+}
+
+// hostname returns the host name for the supplied host name template, which
+// may contain {service}, {region} and {dnsSuffix} placeholders
+func (p *partitionSpec) hostname(
+	template string,
+	dnsSuffix string,
+	service string,
+	region string,
+) string {
+	if dnsSuffix == "" {
+		dnsSuffix = p.DNSSuffix
+	}
+	return strings.NewReplacer(
+		"{service}", service,
+		"{region}", region,
+		"{dnsSuffix}", dnsSuffix,
+	).Replace(template)
+}
+
+// variantHostname returns the host name template of an endpoint variant,
+// which defaults to that of the service's or partition's variant with the
+// same tags
+func (p *partitionSpec) variantHostname(
+	v endpointVariantSpec,
+	svcDefaults partitionEndpointSpec,
+) string {
+	if v.Hostname != "" {
+		return v.Hostname
+	}
+	if def := svcDefaults.variant(v.Tags); def != nil && def.Hostname != "" {
+		return def.Hostname
+	}
+	if def := p.Defaults.variant(v.Tags); def != nil {
+		return def.Hostname
+	}
+	return p.Defaults.Hostname
+}
+
+// variantDNSSuffix returns the DNS suffix of an endpoint variant, which
+// defaults to that of the service's or partition's variant with the same tags
+func (p *partitionSpec) variantDNSSuffix(
+	v endpointVariantSpec,
+	svcDefaults partitionEndpointSpec,
+) string {
+	if v.DNSSuffix != "" {
+		return v.DNSSuffix
+	}
+	if def := svcDefaults.variant(v.Tags); def != nil && def.DNSSuffix != "" {
+		return def.DNSSuffix
+	}
+	if def := p.Defaults.variant(v.Tags); def != nil {
+		return def.DNSSuffix
+	}
+	return ""
+}
+
+// sameTags returns true if the supplied variant tags are the same regardless
+// of order
+func sameTags(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, tag := range a {
+		if !inStrings(tag, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// servers returns the OpenAPI servers of the API. Each partition has a server
+// whose URL contains a {region} variable enumerating the regions whose
+// standard endpoint follows the partition's host name template, plus a
+// server for each global endpoint or region with a host name that does not
+// follow the template. Variants, deprecated endpoints and local endpoints
+// are omitted.
+func (a *API) servers() (oai.Servers, error) {
+	endpoints, err := a.GetEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	service := a.apiSpec.Metadata.EndpointPrefix
+	partitions := []string{}
+	byPartition := map[string][]*Endpoint{}
+	for _, endpoint := range endpoints {
+		if len(endpoint.Variants) > 0 || endpoint.Deprecated || endpoint.Local() {
+			continue
+		}
+		if _, found := byPartition[endpoint.Partition]; !found {
+			partitions = append(partitions, endpoint.Partition)
+		}
+		byPartition[endpoint.Partition] = append(byPartition[endpoint.Partition], endpoint)
+	}
+	servers := oai.Servers{}
+	for _, partition := range partitions {
+		var templated *Endpoint
+		regions := []interface{}{}
+		others := oai.Servers{}
+		for _, endpoint := range byPartition[partition] {
+			// The host name of a region's endpoint follows the template if
+			// the region appears in place of the {region} placeholder
+			if !endpoint.Global && strings.Contains(endpoint.Hostname, "."+endpoint.Region+".") {
+				if templated == nil {
+					templated = endpoint
+				}
+				if strings.Replace(endpoint.URL(), endpoint.Region, "{region}", 1) ==
+					strings.Replace(templated.URL(), templated.Region, "{region}", 1) {
+					regions = append(regions, endpoint.Region)
+					continue
+				}
+			}
+			others = append(others, &oai.Server{
+				URL:         endpoint.URL(),
+				Description: fmt.Sprintf("%s (%s)", endpoint.PartitionName, endpoint.Region),
+			})
+		}
+		if templated != nil {
+			dflt := regions[0]
+			for _, region := range regions {
+				if region == defaultServerRegion {
+					dflt = region
+				}
+			}
+			servers = append(servers, &oai.Server{
+				URL:         strings.Replace(templated.URL(), templated.Region, "{region}", 1),
+				Description: templated.PartitionName,
+				Variables: map[string]*oai.ServerVariable{
+					"region": {
+						Enum:        regions,
+						Default:     dflt,
+						Description: fmt.Sprintf("%s region of the %s endpoint", templated.PartitionName, service),
+					},
+				},
+			})
+		}
+		servers = append(servers, others...)
+	}
+	return servers, nil
+}
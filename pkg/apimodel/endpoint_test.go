@@ -0,0 +1,131 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"testing"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const dynamodbModel = `{
+  "metadata": {
+    "apiVersion": "2012-08-10",
+    "endpointPrefix": "dynamodb",
+    "jsonVersion": "1.0",
+    "protocol": "json",
+    "serviceFullName": "Amazon DynamoDB",
+    "serviceId": "DynamoDB",
+    "signatureVersion": "v4",
+    "targetPrefix": "DynamoDB_20120810"
+  },
+  "operations": {
+    "ListTables": {
+      "name": "ListTables",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "ListTablesInput"}
+    }
+  },
+  "shapes": {
+    "ListTablesInput": {
+      "type": "structure",
+      "members": {"Limit": {"shape": "Integer"}}
+    },
+    "Integer": {"type": "integer"}
+  }
+}`
+
+const testEndpoints = `{
+  "partitions": [
+    {
+      "partition": "aws",
+      "partitionName": "AWS Standard",
+      "dnsSuffix": "amazonaws.com",
+      "defaults": {
+        "hostname": "{service}.{region}.{dnsSuffix}",
+        "protocols": ["https"]
+      },
+      "services": {
+        "dynamodb": {
+          "endpoints": {
+            "eu-west-1": {},
+            "local": {
+              "hostname": "localhost:8000",
+              "protocols": ["http"],
+              "credentialScope": {"region": "us-east-1"}
+            },
+            "us-east-1": {},
+            "us-east-1-fips": {"hostname": "dynamodb-fips.us-east-1.amazonaws.com"}
+          }
+        }
+      }
+    }
+  ]
+}`
+
+func TestServers(t *testing.T) {
+	src := model.NewMemorySource()
+	src.Add("dynamodb", testAPIVersion, model.ModelFile, []byte(dynamodbModel))
+	src.SetEndpoints([]byte(testEndpoints))
+	api, err := New("dynamodb", model.NewSDKHelperFromSource(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	servers, err := api.servers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Neither DynamoDB Local nor the legacy FIPS pseudo-region are servers
+	if len(servers) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(servers))
+	}
+	server := servers[0]
+	if server.URL != "https://dynamodb.{region}.amazonaws.com" {
+		t.Errorf("expected templated URL, got %s", server.URL)
+	}
+	region := server.Variables["region"]
+	if region == nil || len(region.Enum) != 2 || region.Default != defaultServerRegion {
+		t.Errorf("expected region variable enumerating eu-west-1 and us-east-1, got %v", region)
+	}
+	// The local endpoint is still one of the API's endpoints
+	endpoints, err := api.GetEndpoints()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	local := 0
+	for _, endpoint := range endpoints {
+		if endpoint.Local() {
+			local++
+			if endpoint.URL() != "http://localhost:8000" {
+				t.Errorf("expected local URL http://localhost:8000, got %s", endpoint.URL())
+			}
+		}
+	}
+	if local != 1 {
+		t.Errorf("expected 1 local endpoint, got %d", local)
+	}
+}
+
+func TestEndpointLocal(t *testing.T) {
+	tests := []struct {
+		region   string
+		hostname string
+		local    bool
+	}{
+		{"local", "localhost:8000", true},
+		{"us-east-1", "localhost", true},
+		{"us-east-1", "127.0.0.1:4566", true},
+		{"us-east-1", "dynamodb.us-east-1.amazonaws.com", false},
+		{"aws-global", "service.chime.aws.amazon.com", false},
+	}
+	for _, test := range tests {
+		e := &Endpoint{Region: test.region, Hostname: test.hostname}
+		if e.Local() != test.local {
+			t.Errorf("%s %s: expected Local() %v", test.region, test.hostname, test.local)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"fmt"
+	"regexp"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// defaultErrorResponseName is the name of the component response describing
+// the generic AWS error that any operation may return
+const defaultErrorResponseName = "AWSError"
+
+// defaultErrorStatusCode is the HTTP status code of errors whose shapes do
+// not specify one
+const defaultErrorStatusCode = 400
+
+// errorCode returns the code identifying an error shape in error responses,
+// which is the shape name unless the shape specifies a different code
+func errorCode(errShapeName string, errShape *shapeSpec) string {
+	if errShape.Error != nil && errShape.Error.Code != "" {
+		return errShape.Error.Code
+	}
+	return errShapeName
+}
+
+// errorStatusCode returns the HTTP status code of responses for an error
+// shape.
+//
+// Some older XML APIs like S3 do not have an Error field in the shape spec.
+// Instead, the shape spec will have no members, no HTTP response code,
+// nothing but the name of the error.
+//
+// For example:
+//
+//	"NoSuchKey":{
+//	  "type":"structure",
+//	  "members":{
+//	  },
+//	  "exception":true
+//	},
+//
+// In these cases, we just need to use a generic 400 HTTP status code (even
+// though many are actually 404s)
+func errorStatusCode(errShape *shapeSpec) int {
+	if errShape.Error == nil || errShape.Error.HTTPStatusCode == nil {
+		return defaultErrorStatusCode
+	}
+	return *errShape.Error.HTTPStatusCode
+}
+
+// errorExtensions adds the x-aws-error-code and, for shapes that say whether
+// the sender is at fault, x-aws-sender-fault extensions to the schema of an
+// exception shape
+func errorExtensions(schema *oai.Schema, errShapeName string, errShape *shapeSpec) {
+	addExtension(&schema.ExtensionProps, "x-aws-error-code", errorCode(errShapeName, errShape))
+	if errShape.Error != nil {
+		addExtension(&schema.ExtensionProps, "x-aws-sender-fault", errShape.Error.SenderFault)
+	}
+}
+
+// stringSchema returns a string schema whose only allowed value is the
+// supplied value, or any string if the value is empty
+func stringSchema(value string) *oai.Schema {
+	schema := oai.NewStringSchema()
+	if value != "" {
+		schema.WithEnum(value)
+	}
+	return schema
+}
+
+// errorEnvelope returns the schema of the body of the API's error responses.
+// errRef is the schema of the error shape, whose members are part of the
+// error, and code its error code, or errRef is nil and code empty for the
+// generic AWS error.
+//
+// JSON protocol errors are objects with the code in a __type field and a
+// message field. XML protocol errors are an <Error> element with <Type>,
+// <Code> and <Message> elements, wrapped in an <ErrorResponse> element with
+// the <RequestId> for the query and REST-XML protocols, except for S3 whose
+// <Error> element is the root, and in a <Response><Errors> element with the
+// <RequestID> for the EC2 protocol.
+func (m *metadataSpec) errorEnvelope(
+	errRef *oai.SchemaRef,
+	code string,
+	senderFault bool,
+) *oai.Schema {
+	withShape := func(fields *oai.Schema) *oai.Schema {
+		if errRef == nil {
+			return fields
+		}
+		return &oai.Schema{
+			AllOf: []*oai.SchemaRef{errRef, oai.NewSchemaRef("", fields)},
+		}
+	}
+	if !m.isXML() {
+		// Some APIs namespace the code in the __type field, e.g.
+		// "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException"
+		errType := oai.NewStringSchema()
+		if code != "" {
+			errType.WithPattern("(^|#)" + regexp.QuoteMeta(code) + "$")
+		}
+		fields := oai.NewObjectSchema()
+		fields.WithProperty("__type", errType)
+		fields.WithProperty("message", oai.NewStringSchema())
+		if m.Protocol == ProtocolJSON {
+			fields.Required = []string{"__type"}
+		}
+		return withShape(fields)
+	}
+	fields := oai.NewObjectSchema()
+	errType := oai.NewStringSchema().WithEnum("Sender", "Receiver")
+	if senderFault {
+		errType = stringSchema("Sender")
+	}
+	fields.WithProperty("Type", errType)
+	fields.WithProperty("Code", stringSchema(code))
+	fields.WithProperty("Message", oai.NewStringSchema())
+	fields.Required = []string{"Code"}
+	if m.EndpointPrefix == "s3" {
+		fields.WithProperty("RequestId", oai.NewStringSchema())
+		fields.WithProperty("HostId", oai.NewStringSchema())
+		envelope := withShape(fields)
+		envelope.XML = &xmlObject{Name: "Error"}
+		return envelope
+	}
+	errElem := withShape(fields)
+	errElem.XML = &xmlObject{Name: "Error"}
+	envelope := oai.NewObjectSchema()
+	if m.Protocol == ProtocolEC2 {
+		errs := oai.NewObjectSchema()
+		errs.WithProperty("Error", errElem)
+		envelope.WithProperty("Errors", errs)
+		envelope.WithProperty("RequestID", oai.NewStringSchema())
+		envelope.Required = []string{"Errors", "RequestID"}
+		envelope.XML = &xmlObject{Name: "Response"}
+		return envelope
+	}
+	envelope.WithProperty("Error", errElem)
+	envelope.WithProperty("RequestId", oai.NewStringSchema())
+	envelope.Required = []string{"Error", "RequestId"}
+	envelope.XML = &xmlObject{Name: "ErrorResponse"}
+	return envelope
+}
+
+// defaultErrorResponse returns the component response describing the generic
+// AWS error that any operation of the API may return
+func (m *metadataSpec) defaultErrorResponse() *oai.Response {
+	content := oai.NewContent()
+	content[m.responseMediaType()] = oai.NewMediaType().WithSchema(m.errorEnvelope(nil, "", false))
+	return oai.NewResponse().
+		WithDescription("An error returned by AWS that the operation does not model, e.g. an authentication or throttling error").
+		WithContent(content)
+}
+
+// defaultErrorResponseRef returns a reference to the component response
+// describing the generic AWS error
+func defaultErrorResponseRef(swagger *oai.Swagger) *oai.ResponseRef {
+	return &oai.ResponseRef{
+		Ref:   "#/components/responses/" + defaultErrorResponseName,
+		Value: swagger.Components.Responses[defaultErrorResponseName].Value,
+	}
+}
+
+// errorResponses returns the OpenAPI error responses, keyed by HTTP status
+// code, for the supplied error shape references of an operation. Due to the
+// way OpenAPI3 Operations are structured, errors that occur with the same
+// HTTP status code are described by a oneOf their error envelopes.
+func errorResponses(
+	errRefs []*shapeRefSpec,
+	apiSpec *apiSpec,
+) (map[int]*oai.Response, error) {
+	meta := &apiSpec.Metadata
+	codeSchemaMap := map[int][]*oai.SchemaRef{}
+	for _, errRef := range errRefs {
+		if errRef.ShapeName == nil {
+			return nil, fmt.Errorf("expected to find error shape name but found %v", errRef)
+		}
+		errShapeName := *errRef.ShapeName
+		errShape, found := apiSpec.Shapes[errShapeName]
+		if !found {
+			return nil, fmt.Errorf("expected to find error shape %s", errShapeName)
+		}
+		senderFault := errShape.Error != nil && errShape.Error.SenderFault
+		envelope := meta.errorEnvelope(
+			componentSchemaRef(errShapeName),
+			errorCode(errShapeName, errShape),
+			senderFault,
+		)
+		statusCode := errorStatusCode(errShape)
+		codeSchemaMap[statusCode] = append(codeSchemaMap[statusCode], oai.NewSchemaRef("", envelope))
+	}
+	res := make(map[int]*oai.Response, len(codeSchemaMap))
+	for statusCode, schemaRefs := range codeSchemaMap {
+		respSchemaRef := schemaRefs[0]
+		if len(schemaRefs) > 1 {
+			respSchemaRef = oai.NewSchemaRef("", &oai.Schema{OneOf: schemaRefs})
+		}
+		content := oai.NewContent()
+		content[meta.responseMediaType()] = oai.NewMediaType().WithSchemaRef(respSchemaRef)
+		res[statusCode] = oai.NewResponse().WithContent(content)
+	}
+	return res, nil
+}
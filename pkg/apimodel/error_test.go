@@ -0,0 +1,125 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// errorCodes returns the error codes of the error envelopes described by an
+// error response, failing the test if an envelope does not refer to the
+// schema of its error shape
+func errorCodes(t *testing.T, resp *oai.Response) []string {
+	t.Helper()
+	if len(resp.Content) != 1 {
+		t.Fatalf("expected a single media type, got %d", len(resp.Content))
+	}
+	res := []string{}
+	for _, mt := range resp.Content {
+		envelopes := []*oai.SchemaRef{mt.Schema}
+		if oneOf := mt.Schema.Value.OneOf; len(oneOf) > 0 {
+			envelopes = oneOf
+		}
+		for _, envelope := range envelopes {
+			allOf := envelope.Value.AllOf
+			if len(allOf) != 2 {
+				t.Fatalf("expected the error shape and envelope fields, got %d schemas", len(allOf))
+			}
+			code := allOf[1].Value.Properties["Code"].Value.Enum[0].(string)
+			if allOf[0].Ref != "#/components/schemas/"+code {
+				t.Errorf("expected error %s to refer to its shape, got %s", code, allOf[0].Ref)
+			}
+			res = append(res, code)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestErrorResponses(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "s3", s3Model))
+	tests := []struct {
+		operationID string
+		// responses maps the status codes of the operation's responses to
+		// the codes of their errors
+		responses map[string]string
+	}{
+		{
+			"GetObject",
+			map[string]string{"200": "", "400": "NoSuchKey"},
+		},
+		// DeleteBucket has no output, but still returns its errors
+		{
+			"DeleteBucket",
+			map[string]string{"204": "", "400": "NoSuchBucket", "409": "BucketNotEmpty"},
+		},
+		{
+			"CreateMultipartUpload",
+			map[string]string{"200": ""},
+		},
+	}
+	for _, test := range tests {
+		_, op := findOperation(t, swagger, test.operationID)
+		if len(op.Responses) != len(test.responses)+1 {
+			t.Errorf("%s: expected %d responses, got %d", test.operationID, len(test.responses)+1, len(op.Responses))
+		}
+		for code, errCodes := range test.responses {
+			respRef, found := op.Responses[code]
+			if !found {
+				t.Errorf("%s: expected a %s response", test.operationID, code)
+				continue
+			}
+			if errCodes == "" {
+				continue
+			}
+			if got := strings.Join(errorCodes(t, respRef.Value), ","); got != errCodes {
+				t.Errorf("%s: expected %s response errors %s, got %s", test.operationID, code, errCodes, got)
+			}
+		}
+		// Any operation may return errors it does not model
+		if dflt := op.Responses["default"]; dflt == nil || dflt.Ref != "#/components/responses/"+defaultErrorResponseName {
+			t.Errorf("%s: expected the default error response, got %v", test.operationID, dflt)
+		}
+	}
+}
+
+func TestErrorEnvelopeSenderFault(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "s3", s3Model))
+	_, op := findOperation(t, swagger, "DeleteBucket")
+	tests := []struct {
+		code       string
+		errorTypes []interface{}
+	}{
+		// Errors that do not say who is at fault may be of either type
+		{"400", []interface{}{"Sender", "Receiver"}},
+		{"409", []interface{}{"Sender"}},
+	}
+	for _, test := range tests {
+		for _, mt := range op.Responses[test.code].Value.Content {
+			envelope := mt.Schema.Value
+			// S3 errors have the <Error> element as the root
+			if xml, _ := envelope.XML.(*xmlObject); xml == nil || xml.Name != "Error" {
+				t.Errorf("%s: expected an <Error> root element, got %v", test.code, envelope.XML)
+			}
+			got := envelope.AllOf[1].Value.Properties["Type"].Value.Enum
+			if len(got) != len(test.errorTypes) {
+				t.Errorf("%s: expected error types %v, got %v", test.code, test.errorTypes, got)
+				continue
+			}
+			for x := range got {
+				if got[x] != test.errorTypes[x] {
+					t.Errorf("%s: expected error types %v, got %v", test.code, test.errorTypes, got)
+					break
+				}
+			}
+		}
+	}
+}
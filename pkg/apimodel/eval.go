@@ -10,10 +10,16 @@ import (
 	oai "github.com/getkin/kin-openapi/openapi3"
 )
 
-func (api *API) eval() error {
-	if api.swagger != nil {
+// eval evaluates the API's objects and OpenAPI schema using the supplied
+// SchemaOptions. When opts is nil, a previously evaluated schema is reused
+// regardless of its options.
+func (api *API) eval(opts *SchemaOptions) error {
+	if api.swagger != nil && (opts == nil || *opts == api.schemaOpts) {
 		return nil
 	}
+	if opts == nil {
+		opts = DefaultSchemaOptions()
+	}
 
 	// Our goal is to convert the CORAL/upstream model into an OpenAPI3
 	// specification. We make a number of passes over the raw parsed JSON from
@@ -23,11 +29,16 @@ func (api *API) eval() error {
 	spec := api.apiSpec
 	objectMap := map[string]*Object{}
 	comps := &swagger.Components
+	comps.Responses = map[string]*oai.ResponseRef{
+		defaultErrorResponseName: {Value: spec.Metadata.defaultErrorResponse()},
+	}
 
 	for shapeName, shapeSpec := range spec.Shapes {
 		var objType string
-		// Determine simple types like scalars, lists and exceptions
-		if shapeSpec.Type != "structure" && shapeSpec.Type != "list" {
+		// Determine simple types like scalars, lists, maps and exceptions
+		if shapeSpec.Type == "map" {
+			objType = ObjectTypeMap
+		} else if shapeSpec.Type != "structure" && shapeSpec.Type != "list" {
 			objType = ObjectTypeScalar
 		} else if shapeSpec.Type == "structure" {
 			if shapeSpec.Exception {
@@ -50,22 +61,50 @@ func (api *API) eval() error {
 		}
 		comps.Schemas[shapeName] = oai.NewSchemaRef("", schema)
 	}
+	api.addSchemaDocs(comps.Schemas, opts.DocFormat)
 	api.objectMap = objectMap
 
+	servers, err := api.servers()
+	if err != nil {
+		return err
+	}
+	if len(servers) > 0 {
+		swagger.Servers = servers
+	}
+	spec.Metadata.addSecurity(swagger, spec.Operations)
+	opWaiters := waitersExtensions(spec.Waiters)
+	uris := make(map[string]*requestURI, len(spec.Operations))
+	for opName, opSpec := range spec.Operations {
+		uris[opName] = parseRequestURI(*opSpec.HTTP.RequestURI)
+	}
+	// Many AWS APIs only really use a single HTTP verb (usually POST) and
+	// URI (usually /) and vary operations by an "action" parameter, and
+	// REST APIs like S3 vary operations on the same path by static query
+	// string parameters, so some operations need a disambiguated path
+	paths := operationPaths(spec.Operations, uris)
+	// With the header and callback RPC styles, all operations of RPC-style
+	// APIs are described by a single operation on "/"
+	dispatch := opts.RPCStyle != RPCStyleFragment && spec.Metadata.isRPC()
+	rpcOps := map[string]*oai.Operation{}
 	for opName, opSpec := range spec.Operations {
-		doc := api.docSpec.Operations[opName]
+		doc := formatDoc(api.docSpec.Operations[opName], opts.DocFormat)
 		op, err := opSpec.Operation(opName, doc, swagger, spec)
 		if err != nil {
 			return err
 		}
-		// See https://github.com/OAI/OpenAPI-Specification/issues/1635#issuecomment-607444697
-		// Many AWS APIs only really use a single HTTP verb (usually POST) and
-		// URI (usually /) and vary operations by an "action" parameter.
-		// Therefore, for these APIs, we embed a fragment into the URI in order
-		// to allow OpenAPI/Swagger to include these as separate operations.
-		reqURI := *opSpec.HTTP.RequestURI
-		reqURI += "#" + opName
-		swagger.AddOperation(reqURI, opSpec.HTTP.Method, op)
+		api.addParameterDocs(op, opSpec, opts.DocFormat)
+		if pagSpec, found := spec.Paginators[opName]; found {
+			addExtension(&op.ExtensionProps, "x-aws-pagination", paginationExtension(pagSpec))
+		}
+		if waiters, found := opWaiters[opName]; found {
+			addExtension(&op.ExtensionProps, "x-aws-waiters", waiters)
+		}
+		uris[opName].applyTo(op)
+		if dispatch {
+			rpcOps[opName] = op
+		} else {
+			swagger.AddOperation(paths[opName], opSpec.HTTP.Method, op)
+		}
 		if opSpec.Input != nil && opSpec.Input.ShapeName != nil {
 			inShapeName := *opSpec.Input.ShapeName
 			shapeSpec := spec.Shapes[inShapeName]
@@ -85,7 +124,11 @@ func (api *API) eval() error {
 			}
 		}
 	}
+	if dispatch {
+		swagger.AddOperation("/", "POST", rpcDispatchOperation(rpcOps, &spec.Metadata, swagger.Security, opts.RPCStyle))
+	}
 
 	api.swagger = swagger
+	api.schemaOpts = *opts
 	return nil
 }
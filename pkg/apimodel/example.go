@@ -0,0 +1,101 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+type exampleSpec struct {
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Input       interface{} `json:"input"`
+	Output      interface{} `json:"output"`
+}
+
+type examplesSpec struct {
+	Examples map[string][]*exampleSpec `json:"examples"`
+}
+
+// parseExamples returns the examples, keyed by operation name, in the
+// supplied examples-1.json document
+func parseExamples(b []byte) (map[string][]*exampleSpec, error) {
+	var spec examplesSpec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode examples: %v", err)
+	}
+	return spec.Examples, nil
+}
+
+// Example is a sample request to and response from an API operation
+type Example struct {
+	// Name uniquely identifies the example within its operation
+	Name        string      `json:"name"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Input       interface{} `json:"input,omitempty"`
+	Output      interface{} `json:"output,omitempty"`
+}
+
+// exampleName returns the name of the example at index x of an operation's
+// examples, which is the example's ID if it has one
+func exampleName(opName string, x int, spec *exampleSpec) string {
+	if spec.ID != "" {
+		return spec.ID
+	}
+	return opName + "-" + strconv.Itoa(x+1)
+}
+
+// GetExamples returns the examples for the supplied operation name, in the
+// order they appear in the API model
+func (a *API) GetExamples(opName string) []*Example {
+	specs := a.apiSpec.Examples[opName]
+	res := make([]*Example, len(specs))
+	for x, spec := range specs {
+		res[x] = &Example{
+			Name:        exampleName(opName, x, spec),
+			Title:       spec.Title,
+			Description: spec.Description,
+			Input:       spec.Input,
+			Output:      spec.Output,
+		}
+	}
+	return res
+}
+
+// addExamples adds the input or output of each of an operation's examples to
+// the supplied OpenAPI media type, which may be nil if the request or
+// response has no JSON body
+func addExamples(
+	mediaType *oai.MediaType,
+	opName string,
+	specs []*exampleSpec,
+	output bool,
+) {
+	if mediaType == nil {
+		return
+	}
+	for x, spec := range specs {
+		value := spec.Input
+		if output {
+			value = spec.Output
+		}
+		if value == nil {
+			continue
+		}
+		name := exampleName(opName, x, spec)
+		mediaType.WithExample(name, value)
+		example := mediaType.Examples[name].Value
+		example.Summary = spec.Title
+		example.Description = spec.Description
+	}
+}
@@ -0,0 +1,120 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const dynamodbExamples = `{
+  "version": "1.0",
+  "examples": {
+    "DescribeTable": [
+      {
+        "input": {"TableName": "Music"},
+        "title": "To describe a table"
+      }
+    ],
+    "ListTables": [
+      {
+        "input": {},
+        "output": {"TableNames": ["Forum", "Music"]},
+        "description": "This example lists all of the tables.",
+        "id": "to-list-all-tables-1472059565734",
+        "title": "To list tables"
+      }
+    ]
+  }
+}`
+
+// mediaTypeOf returns the single media type of the supplied content, failing
+// the test if there is not exactly one
+func mediaTypeOf(t *testing.T, content oai.Content) *oai.MediaType {
+	t.Helper()
+	if len(content) != 1 {
+		t.Fatalf("expected a single media type, got %d", len(content))
+	}
+	for _, mt := range content {
+		return mt
+	}
+	return nil
+}
+
+func TestGetExamples(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:    dynamodbTablesModel,
+		model.ExamplesFile: dynamodbExamples,
+	})
+	tests := []struct {
+		operationID string
+		want        []*Example
+	}{
+		{"ListTables", []*Example{{
+			Name:        "to-list-all-tables-1472059565734",
+			Title:       "To list tables",
+			Description: "This example lists all of the tables.",
+			Input:       map[string]interface{}{},
+			Output:      map[string]interface{}{"TableNames": []interface{}{"Forum", "Music"}},
+		}}},
+		// Examples without an ID are named after the operation
+		{"DescribeTable", []*Example{{
+			Name:  "DescribeTable-1",
+			Title: "To describe a table",
+			Input: map[string]interface{}{"TableName": "Music"},
+		}}},
+	}
+	for _, test := range tests {
+		if got := api.GetExamples(test.operationID); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected examples %+v, got %+v", test.operationID, test.want, got)
+		}
+	}
+}
+
+func TestOperationExamples(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:    dynamodbTablesModel,
+		model.ExamplesFile: dynamodbExamples,
+	})
+	swagger := testSchema(t, api)
+
+	_, op := findOperation(t, swagger, "ListTables")
+	reqExamples := mediaTypeOf(t, op.RequestBody.Value.Content).Examples
+	respExamples := mediaTypeOf(t, op.Responses["200"].Value.Content).Examples
+	name := "to-list-all-tables-1472059565734"
+	if len(reqExamples) != 1 || reqExamples[name] == nil {
+		t.Fatalf("expected request example %s, got %v", name, reqExamples)
+	}
+	if len(respExamples) != 1 || respExamples[name] == nil {
+		t.Fatalf("expected response example %s, got %v", name, respExamples)
+	}
+	example := respExamples[name].Value
+	if example.Summary != "To list tables" || example.Description != "This example lists all of the tables." {
+		t.Errorf("expected the example title and description, got %q and %q", example.Summary, example.Description)
+	}
+	wantOutput := map[string]interface{}{"TableNames": []interface{}{"Forum", "Music"}}
+	if !reflect.DeepEqual(example.Value, wantOutput) {
+		t.Errorf("expected response example %v, got %v", wantOutput, example.Value)
+	}
+	if !reflect.DeepEqual(reqExamples[name].Value.Value, map[string]interface{}{}) {
+		t.Errorf("expected an empty request example, got %v", reqExamples[name].Value.Value)
+	}
+
+	// The DescribeTable example has no output, so only the request has an
+	// example
+	_, op = findOperation(t, swagger, "DescribeTable")
+	if examples := mediaTypeOf(t, op.RequestBody.Value.Content).Examples; examples["DescribeTable-1"] == nil {
+		t.Errorf("expected request example DescribeTable-1, got %v", examples)
+	}
+	if examples := mediaTypeOf(t, op.Responses["200"].Value.Content).Examples; len(examples) != 0 {
+		t.Errorf("expected no response examples, got %v", examples)
+	}
+}
@@ -0,0 +1,249 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Resources are often contained within another resource. For example, in the
+// AWS APIGateway API, a Deployment is solely a part of a RestApi and cannot
+// be created as a separate thing. The request URI of the CreateDeployment
+// operation names the RestApi the Deployment is created in:
+//
+//  "CreateDeployment":{
+//    "name":"CreateDeployment",
+//    "http":{
+//      "method":"POST",
+//      "requestUri":"/restapis/{restapi_id}/deployments",
+//      "responseCode":201
+//    },
+//    ...
+//  },
+//
+// APIs that do not bind members to the request URI name the containing
+// resource with a required member of the Create operation's input instead,
+// e.g. the DatabaseName member of the CreateTableRequest shape in the AWS Glue
+// API. Such a member only names the containing resource when the resource is
+// also needed to identify the contained resource afterwards, like the
+// DatabaseName member of the GetTableRequest shape. Otherwise it merely refers
+// to another resource the new one is created from or with, e.g. the
+// EndpointConfigName member of the CreateEndpointInput shape in the AWS
+// SageMaker API or the VpcId member of the CreateSubnetRequest shape in the
+// AWS EC2 API.
+
+// addHierarchy sets the Parent and Children of the supplied resources
+func (a *API) addHierarchy(resources []*Resource) {
+	candidates := make(map[*Resource][]*Resource, len(resources))
+	for _, r := range resources {
+		if parent := a.uriParent(r, resources); parent != nil {
+			candidates[r] = []*Resource{parent}
+		} else {
+			candidates[r] = a.inputParents(r, resources)
+		}
+	}
+	for _, r := range resources {
+		parent := deepestCandidate(candidates[r], candidates)
+		if parent == nil || parent.hasAncestor(r) {
+			continue
+		}
+		r.Parent = parent
+		parent.Children = append(parent.Children, r)
+	}
+	for _, r := range resources {
+		sort.Slice(r.Children, func(i, j int) bool {
+			return r.Children[i].SingularName < r.Children[j].SingularName
+		})
+	}
+}
+
+// hasAncestor returns true if the supplied resource is the resource itself or
+// one of its ancestors
+func (r *Resource) hasAncestor(ancestor *Resource) bool {
+	for res := r; res != nil; res = res.Parent {
+		if res == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// deepestCandidate returns the candidate parent that is not itself a candidate
+// parent of another of the candidates, e.g. a Table rather than the Database
+// containing it, or nil if there are no candidates
+func deepestCandidate(parents []*Resource, candidates map[*Resource][]*Resource) *Resource {
+	for _, parent := range parents {
+		deepest := true
+		for _, other := range parents {
+			if other != parent && resourceIn(parent, candidates[other]) {
+				deepest = false
+				break
+			}
+		}
+		if deepest {
+			return parent
+		}
+	}
+	return nil
+}
+
+// resourceIn returns true if the resource is one of the supplied resources
+func resourceIn(r *Resource, resources []*Resource) bool {
+	for _, res := range resources {
+		if res == r {
+			return true
+		}
+	}
+	return false
+}
+
+// uriParent returns the resource named by the innermost label in the request
+// URI of the resource's Create operation, other than the resource itself, or
+// nil if there is no such resource. A label names a resource if the path
+// segment before it is the resource's plural name, e.g. "restapis", or the
+// label is bound to a member that identifies the resource, e.g. "restApiId".
+func (a *API) uriParent(r *Resource, resources []*Resource) *Resource {
+	opSpec := a.apiSpec.Operations[r.Create.Name]
+	if opSpec.HTTP == nil || opSpec.HTTP.RequestURI == nil {
+		return nil
+	}
+	segments := strings.Split(parseRequestURI(*opSpec.HTTP.RequestURI).Path, "/")
+	for x := len(segments) - 1; x > 0; x-- {
+		match := uriLabelRegex.FindStringSubmatch(segments[x])
+		if match == nil {
+			continue
+		}
+		var parent *Resource
+		if !uriLabelRegex.MatchString(segments[x-1]) {
+			parent = findResource(resources, func(res *Resource) bool {
+				return sameWords(segments[x-1], res.PluralName) ||
+					sameWords(segments[x-1], res.SingularName)
+			})
+		}
+		if parent == nil {
+			memberName := a.uriMember(opSpec, match[1])
+			parent = findResource(resources, func(res *Resource) bool {
+				return identifiesByName(memberName, res.SingularName)
+			})
+		}
+		if parent != nil && parent != r {
+			return parent
+		}
+	}
+	return nil
+}
+
+// uriMember returns the name of the member of the operation's input that is
+// bound to the supplied request URI label, or the empty string if there is no
+// such member
+func (a *API) uriMember(opSpec *opSpec, label string) string {
+	if opSpec.Input == nil || opSpec.Input.ShapeName == nil {
+		return ""
+	}
+	inShape, found := a.apiSpec.Shapes[*opSpec.Input.ShapeName]
+	if !found {
+		return ""
+	}
+	for memberName, ref := range inShape.Members {
+		if memberLocation(ref) == locationURI && wireName(memberName, ref) == label {
+			return memberName
+		}
+	}
+	return ""
+}
+
+// inputParents returns the resources, other than the resource itself, that
+// are identified by the required members of the input of the resource's
+// Create operation and that are also needed to identify the resource. Lists
+// of identifiers, e.g. the SubnetIds member of the
+// CreateTransitGatewayVpcAttachmentRequest shape in the AWS EC2 API, refer
+// to resources without being contained within them.
+func (a *API) inputParents(r *Resource, resources []*Resource) []*Resource {
+	res := []*Resource{}
+	if r.Create.Input == nil {
+		return res
+	}
+	inShape, found := a.apiSpec.Shapes[r.Create.Input.ShapeName]
+	if !found {
+		return res
+	}
+	memberNames := append([]string{}, inShape.Required...)
+	sort.Strings(memberNames)
+	for _, memberName := range memberNames {
+		if a.isListShape(inShape.Members[memberName]) {
+			continue
+		}
+		parent := findResource(resources, func(res *Resource) bool {
+			return identifiesByName(memberName, res.SingularName)
+		})
+		if parent != nil && parent != r && !resourceIn(parent, res) &&
+			a.identifiedWithin(r, parent) {
+			res = append(res, parent)
+		}
+	}
+	return res
+}
+
+// identifiedWithin returns true if a required member of the input of the
+// resource's ReadOne or Delete operation identifies the supplied parent,
+// i.e. if the resource is identified within the parent
+func (a *API) identifiedWithin(r *Resource, parent *Resource) bool {
+	for _, op := range []*Operation{r.ReadOne, r.Delete} {
+		if op == nil || op.Input == nil {
+			continue
+		}
+		inShape, found := a.apiSpec.Shapes[op.Input.ShapeName]
+		if !found {
+			continue
+		}
+		for _, memberName := range inShape.Required {
+			if identifiesByName(memberName, parent.SingularName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findResource returns the first of the resources that matches the supplied
+// function or nil if none do
+func findResource(resources []*Resource, matches func(*Resource) bool) *Resource {
+	for _, res := range resources {
+		if matches(res) {
+			return res
+		}
+	}
+	return nil
+}
+
+// identifiesByName returns true if the member name is an identifier of the
+// named resource made of the resource's full name, e.g. "VpcId" for a Vpc or
+// "domainName" for a DomainName, but not "GroupId" for a SecurityGroup
+func identifiesByName(memberName string, resourceName string) bool {
+	id := splitIdentifier(memberName, resourceName)
+	if id.kind == "" {
+		return false
+	}
+	return strings.EqualFold(memberName, resourceName) ||
+		strings.EqualFold(id.prefix, resourceName)
+}
+
+// sameWords returns true if the supplied names are the same ignoring case and
+// separators, e.g. "usage-plans" and "UsagePlans"
+func sameWords(a string, b string) bool {
+	strip := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, s)
+	}
+	return strip(a) != "" && strip(a) == strip(b)
+}
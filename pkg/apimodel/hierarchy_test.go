@@ -0,0 +1,258 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"strings"
+	"testing"
+)
+
+const glueModel = `{
+  "metadata": {
+    "apiVersion": "2017-03-31",
+    "endpointPrefix": "glue",
+    "jsonVersion": "1.1",
+    "protocol": "json",
+    "serviceFullName": "AWS Glue",
+    "serviceId": "Glue",
+    "signatureVersion": "v4",
+    "targetPrefix": "AWSGlue"
+  },
+  "operations": {
+    "CreateDatabase": {
+      "name": "CreateDatabase",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateDatabaseRequest"},
+      "output": {"shape": "CreateDatabaseResponse"}
+    },
+    "GetDatabase": {
+      "name": "GetDatabase",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "GetDatabaseRequest"},
+      "output": {"shape": "GetDatabaseResponse"}
+    },
+    "DeleteDatabase": {
+      "name": "DeleteDatabase",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "GetDatabaseRequest"}
+    },
+    "CreateTable": {
+      "name": "CreateTable",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateTableRequest"},
+      "output": {"shape": "CreateTableResponse"}
+    },
+    "GetTable": {
+      "name": "GetTable",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "GetTableRequest"},
+      "output": {"shape": "GetTableResponse"}
+    },
+    "DeleteTable": {
+      "name": "DeleteTable",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "GetTableRequest"}
+    }
+  },
+  "shapes": {
+    "CreateDatabaseRequest": {
+      "type": "structure",
+      "required": ["DatabaseName"],
+      "members": {"DatabaseName": {"shape": "String"}}
+    },
+    "CreateDatabaseResponse": {"type": "structure", "members": {}},
+    "GetDatabaseRequest": {
+      "type": "structure",
+      "required": ["Name"],
+      "members": {"Name": {"shape": "String"}}
+    },
+    "GetDatabaseResponse": {
+      "type": "structure",
+      "members": {"Database": {"shape": "Database"}}
+    },
+    "Database": {
+      "type": "structure",
+      "members": {"Name": {"shape": "String"}}
+    },
+    "CreateTableRequest": {
+      "type": "structure",
+      "required": ["DatabaseName", "TableName"],
+      "members": {
+        "DatabaseName": {"shape": "String"},
+        "TableName": {"shape": "String"}
+      }
+    },
+    "CreateTableResponse": {"type": "structure", "members": {}},
+    "GetTableRequest": {
+      "type": "structure",
+      "required": ["DatabaseName", "Name"],
+      "members": {
+        "DatabaseName": {"shape": "String"},
+        "Name": {"shape": "String"}
+      }
+    },
+    "GetTableResponse": {
+      "type": "structure",
+      "members": {"Table": {"shape": "Table"}}
+    },
+    "Table": {
+      "type": "structure",
+      "members": {
+        "DatabaseName": {"shape": "String"},
+        "Name": {"shape": "String"}
+      }
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+const sagemakerModel = `{
+  "metadata": {
+    "apiVersion": "2017-07-24",
+    "endpointPrefix": "api.sagemaker",
+    "jsonVersion": "1.1",
+    "protocol": "json",
+    "serviceFullName": "Amazon SageMaker Service",
+    "serviceId": "SageMaker",
+    "signatureVersion": "v4",
+    "signingName": "sagemaker",
+    "targetPrefix": "SageMaker"
+  },
+  "operations": {
+    "CreateEndpointConfig": {
+      "name": "CreateEndpointConfig",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateEndpointConfigInput"},
+      "output": {"shape": "CreateEndpointConfigOutput"}
+    },
+    "DeleteEndpointConfig": {
+      "name": "DeleteEndpointConfig",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DeleteEndpointConfigInput"}
+    },
+    "CreateEndpoint": {
+      "name": "CreateEndpoint",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateEndpointInput"},
+      "output": {"shape": "CreateEndpointOutput"}
+    },
+    "DescribeEndpoint": {
+      "name": "DescribeEndpoint",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeEndpointInput"},
+      "output": {"shape": "DescribeEndpointOutput"}
+    },
+    "DeleteEndpoint": {
+      "name": "DeleteEndpoint",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeEndpointInput"}
+    }
+  },
+  "shapes": {
+    "CreateEndpointConfigInput": {
+      "type": "structure",
+      "required": ["EndpointConfigName"],
+      "members": {"EndpointConfigName": {"shape": "String"}}
+    },
+    "CreateEndpointConfigOutput": {
+      "type": "structure",
+      "members": {"EndpointConfigArn": {"shape": "String"}}
+    },
+    "DeleteEndpointConfigInput": {
+      "type": "structure",
+      "required": ["EndpointConfigName"],
+      "members": {"EndpointConfigName": {"shape": "String"}}
+    },
+    "CreateEndpointInput": {
+      "type": "structure",
+      "required": ["EndpointName", "EndpointConfigName"],
+      "members": {
+        "EndpointName": {"shape": "String"},
+        "EndpointConfigName": {"shape": "String"}
+      }
+    },
+    "CreateEndpointOutput": {
+      "type": "structure",
+      "members": {"EndpointArn": {"shape": "String"}}
+    },
+    "DescribeEndpointInput": {
+      "type": "structure",
+      "required": ["EndpointName"],
+      "members": {"EndpointName": {"shape": "String"}}
+    },
+    "DescribeEndpointOutput": {
+      "type": "structure",
+      "members": {
+        "EndpointName": {"shape": "String"},
+        "EndpointArn": {"shape": "String"},
+        "EndpointConfigName": {"shape": "String"}
+      }
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+// resourceNames returns the singular names of the supplied resources
+func resourceNames(resources []*Resource) string {
+	names := []string{}
+	for _, r := range resources {
+		names = append(names, r.SingularName)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestResourceHierarchy(t *testing.T) {
+	tests := []struct {
+		serviceAlias string
+		modelJSON    string
+		resource     string
+		parent       string
+		children     string
+	}{
+		{"apigateway", apigatewayModel, "RestApi", "", "Deployment,Resource,Stage"},
+		// The "restapis" path segment before the {restapi_id} label names
+		// the parent
+		{"apigateway", apigatewayModel, "Deployment", "RestApi", ""},
+		// The request URI wins over the deploymentId member of the input
+		{"apigateway", apigatewayModel, "Stage", "RestApi", ""},
+		// The {parent_id} label after the "resources" path segment names a
+		// Resource, but not another resource, so the RestApi is the parent
+		{"apigateway", apigatewayModel, "Resource", "RestApi", ""},
+		// The Glue API does not bind members to the request URI, so the
+		// required DatabaseName member of the input names the parent, as
+		// reading and deleting a Table requires the DatabaseName too
+		{"glue", glueModel, "Database", "", "Table"},
+		{"glue", glueModel, "Table", "Database", ""},
+		// An Endpoint is created from an EndpointConfig, but it is identified
+		// by its name alone, so it is not contained within the EndpointConfig
+		{"sagemaker", sagemakerModel, "EndpointConfig", "", ""},
+		{"sagemaker", sagemakerModel, "Endpoint", "", ""},
+		// Neither is a Subnet identified within the Vpc named by the
+		// required VpcId member of CreateSubnetRequest
+		{"ec2", ec2Model, "Vpc", "", ""},
+		{"ec2", ec2Model, "Subnet", "", ""},
+		// The VpcId member of CreateSecurityGroupRequest is not required
+		{"ec2", ec2Model, "SecurityGroup", "", ""},
+	}
+	for _, test := range tests {
+		api := newTestAPI(t, test.serviceAlias, test.modelJSON)
+		r := api.GetResource(test.resource)
+		if r == nil {
+			t.Fatalf("%s: expected resource %s", test.serviceAlias, test.resource)
+		}
+		parent := ""
+		if r.Parent != nil {
+			parent = r.Parent.SingularName
+		}
+		if parent != test.parent {
+			t.Errorf("%s: expected parent %q, got %q", test.resource, test.parent, parent)
+		}
+		if children := resourceNames(r.Children); children != test.children {
+			t.Errorf("%s: expected children %q, got %q", test.resource, test.children, children)
+		}
+	}
+}
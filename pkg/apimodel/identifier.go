@@ -0,0 +1,385 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The kinds of the members that identify resources
+const (
+	IdentifierKindARN  = "arn"
+	IdentifierKindID   = "id"
+	IdentifierKindURL  = "url"
+	IdentifierKindName = "name"
+)
+
+// identifierKinds are the kinds of identifiers, in order of preference
+var identifierKinds = []string{
+	IdentifierKindARN,
+	IdentifierKindID,
+	IdentifierKindURL,
+	IdentifierKindName,
+}
+
+// identifierSuffixes maps the suffixes of identifier member names to the
+// kind of identifier. "Identifier" is used by e.g. the AWS RDS API for the
+// ID chosen when a resource is created, e.g. "DBInstanceIdentifier". The
+// plural suffixes are those of list members holding several identifiers,
+// e.g. "GroupIds".
+var identifierSuffixes = []struct {
+	suffix string
+	kind   string
+}{
+	{"Identifier", IdentifierKindID},
+	{"Identifiers", IdentifierKindID},
+	{"Arn", IdentifierKindARN},
+	{"Arns", IdentifierKindARN},
+	{"ARN", IdentifierKindARN},
+	{"ARNs", IdentifierKindARN},
+	{"Id", IdentifierKindID},
+	{"Ids", IdentifierKindID},
+	{"ID", IdentifierKindID},
+	{"IDs", IdentifierKindID},
+	{"Url", IdentifierKindURL},
+	{"Urls", IdentifierKindURL},
+	{"URL", IdentifierKindURL},
+	{"URLs", IdentifierKindURL},
+	{"Name", IdentifierKindName},
+	{"Names", IdentifierKindName},
+}
+
+// IdentifierMismatch describes an operation that identifies a resource with
+// a member that is not one of the identifiers returned by the resource's
+// Create operation, e.g. a Delete operation taking a name when the Create
+// operation only returns an ID
+type IdentifierMismatch struct {
+	// OperationType is the type of the operation, e.g. "Delete"
+	OperationType string
+	// Operation is the name of the operation
+	Operation string
+	// Identifier is the member of the operation's input that identifies the
+	// resource
+	Identifier string
+	// Supplied is true if the identifier is also a member of the Create
+	// operation's input, so that the caller chooses it, e.g. the name of the
+	// resource
+	Supplied bool
+}
+
+// ARNIdentified returns true if the resource's primary identifier is an ARN
+func (r *Resource) ARNIdentified() bool {
+	return splitIdentifier(r.PrimaryIdentifier, r.SingularName).kind == IdentifierKindARN
+}
+
+// IdentifierMismatches returns the ReadOne and Delete operations that
+// identify the resource with a member that is not one of its Identifiers
+func (r *Resource) IdentifierMismatches() []*IdentifierMismatch {
+	res := []*IdentifierMismatch{}
+	if len(r.Identifiers) == 0 {
+		return res
+	}
+	checks := []struct {
+		opType     string
+		op         *Operation
+		identifier string
+	}{
+		{OperationTypeReadOne, r.ReadOne, r.ReadOneIdentifier},
+		{OperationTypeDelete, r.Delete, r.DeleteIdentifier},
+	}
+	for _, check := range checks {
+		if check.op == nil || check.identifier == "" {
+			continue
+		}
+		if matchIdentifier(check.identifier, r.Identifiers, r.SingularName) == "" {
+			res = append(res, &IdentifierMismatch{
+				OperationType: check.opType,
+				Operation:     check.op.Name,
+				Identifier:    check.identifier,
+				Supplied:      matchIdentifier(check.identifier, r.createIdentifiers, r.SingularName) != "",
+			})
+		}
+	}
+	return res
+}
+
+// identifier describes a member whose name identifies a resource
+type identifier struct {
+	// prefix is the part of the member's name before the kind suffix, e.g.
+	// "Topic" for "TopicArn"
+	prefix string
+	kind   string
+}
+
+// splitIdentifier returns the prefix and kind of the supplied member name for
+// the named resource. The kind is empty if the member does not look like an
+// identifier. A member named after the resource, e.g. "Bucket" or
+// "domainName" for a DomainName, has no prefix and is a name unless its
+// suffix says otherwise.
+func splitIdentifier(memberName string, resourceName string) identifier {
+	switch lower := strings.ToLower(memberName); lower {
+	case IdentifierKindARN, IdentifierKindID, IdentifierKindURL, IdentifierKindName:
+		return identifier{kind: lower}
+	}
+	res := identifier{}
+	for _, s := range identifierSuffixes {
+		if strings.HasSuffix(memberName, s.suffix) && len(memberName) > len(s.suffix) {
+			res = identifier{
+				prefix: strings.TrimSuffix(memberName, s.suffix),
+				kind:   s.kind,
+			}
+			break
+		}
+	}
+	if strings.EqualFold(memberName, resourceName) {
+		res.prefix = ""
+		if res.kind == "" {
+			res.kind = IdentifierKindName
+		}
+	}
+	return res
+}
+
+// identifies returns true if the identifier is the resource's own, i.e. it
+// has no prefix, e.g. "id", or its prefix is the resource's name or ends
+// the resource's name at a word boundary, e.g. "TopicArn" for a Topic,
+// "restApiId" for a RestApi or "GroupId" for a SecurityGroup
+func (i identifier) identifies(resourceName string) bool {
+	if i.kind == "" {
+		return false
+	}
+	if i.prefix == "" {
+		return true
+	}
+	name := strings.ToLower(resourceName)
+	prefix := strings.ToLower(i.prefix)
+	if !strings.HasSuffix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	return unicode.IsUpper([]rune(resourceName)[len(name)-len(prefix)])
+}
+
+// sameIdentifier returns true if the supplied member names identify the
+// named resource in the same way, e.g. "id" and "restApiId" for a RestApi
+func sameIdentifier(a string, b string, resourceName string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	ia := splitIdentifier(a, resourceName)
+	ib := splitIdentifier(b, resourceName)
+	return ia.kind != "" && ia.kind == ib.kind &&
+		ia.identifies(resourceName) && ib.identifies(resourceName)
+}
+
+// matchIdentifier returns the first of the candidate member names that
+// identifies the named resource in the same way as the supplied member name,
+// or the empty string if none do
+func matchIdentifier(memberName string, candidates []string, resourceName string) string {
+	for _, candidate := range candidates {
+		if sameIdentifier(memberName, candidate, resourceName) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// sortIdentifiers sorts the supplied identifier member names of the named
+// resource by kind, ARNs first
+func sortIdentifiers(memberNames []string, resourceName string) {
+	rank := func(memberName string) int {
+		kind := splitIdentifier(memberName, resourceName).kind
+		for x, k := range identifierKinds {
+			if k == kind {
+				return x
+			}
+		}
+		return len(identifierKinds)
+	}
+	sort.SliceStable(memberNames, func(i, j int) bool {
+		return rank(memberNames[i]) < rank(memberNames[j])
+	})
+}
+
+// shapeIdentifiers returns the names of the scalar members of the named
+// structure shape that identify the named resource, sorted by kind. Only the
+// members bound to the request URI or required are considered if
+// requiredOnly is true.
+func (a *API) shapeIdentifiers(shapeName string, resourceName string, requiredOnly bool) []string {
+	res := []string{}
+	shape, found := a.apiSpec.Shapes[shapeName]
+	if !found {
+		return res
+	}
+	for memberName, ref := range shape.Members {
+		if requiredOnly && !inStrings(memberName, shape.Required) && memberLocation(ref) != locationURI {
+			continue
+		}
+		if ref.ShapeName == nil {
+			continue
+		}
+		target, found := a.apiSpec.Shapes[*ref.ShapeName]
+		if !found || target.Type == "structure" || target.Type == "list" || target.Type == "map" {
+			continue
+		}
+		if splitIdentifier(memberName, resourceName).identifies(resourceName) {
+			res = append(res, memberName)
+		}
+	}
+	sort.Strings(res)
+	sortIdentifiers(res, resourceName)
+	return res
+}
+
+// listIdentifiers returns the names of the members of the named structure
+// shape that are lists of scalars identifying the named resource, e.g.
+// "GroupIds" for a SecurityGroup
+func (a *API) listIdentifiers(shapeName string, resourceName string) []string {
+	res := []string{}
+	shape, found := a.apiSpec.Shapes[shapeName]
+	if !found {
+		return res
+	}
+	for memberName, ref := range shape.Members {
+		if !a.isListShape(ref) {
+			continue
+		}
+		member := a.apiSpec.Shapes[*ref.ShapeName].ListMember
+		if member == nil || member.ShapeName == nil {
+			continue
+		}
+		target, found := a.apiSpec.Shapes[*member.ShapeName]
+		if !found || target.Type == "structure" || target.Type == "list" || target.Type == "map" {
+			continue
+		}
+		if splitIdentifier(memberName, resourceName).identifies(resourceName) {
+			res = append(res, memberName)
+		}
+	}
+	sort.Strings(res)
+	sortIdentifiers(res, resourceName)
+	return res
+}
+
+// addIdentifiers sets the resource's identifiers. Like the CreateTopicResponse
+// shape of the AWS SNS API, which contains a TopicArn, the output of the
+// Create operation typically contains the identifiers of the resource, either
+// directly or in a structure describing the resource, e.g. the Vpc member of
+// the CreateVpcResult shape of the AWS EC2 API. When the output does not, as
+// for the S3 CreateBucket operation, the identifiers are the required members
+// of the Create operation's input.
+func (a *API) addIdentifiers(r *Resource) {
+	s := r.SingularName
+	if r.Create.Input != nil {
+		r.createIdentifiers = a.shapeIdentifiers(r.Create.Input.ShapeName, s, false)
+	}
+	r.Identifiers = a.outputIdentifiers(r.Create, s)
+	if len(r.Identifiers) == 0 && r.Create.Input != nil {
+		r.Identifiers = a.shapeIdentifiers(r.Create.Input.ShapeName, s, true)
+	}
+	r.ReadOneIdentifier = a.inputIdentifier(r.ReadOne, r.Identifiers, s)
+	r.DeleteIdentifier = a.inputIdentifier(r.Delete, r.Identifiers, s)
+	// The primary identifier is the one the Delete or ReadOne operation
+	// takes, if the Create operation returns it
+	for _, memberName := range []string{r.DeleteIdentifier, r.ReadOneIdentifier} {
+		if match := matchIdentifier(memberName, r.Identifiers, s); memberName != "" && match != "" {
+			r.PrimaryIdentifier = match
+			return
+		}
+	}
+	if len(r.Identifiers) > 0 {
+		r.PrimaryIdentifier = r.Identifiers[0]
+	}
+}
+
+// outputIdentifiers returns the names of the members of the operation's output
+// shape, or of the structures or lists of structures in the output shape, that
+// identify the named resource. Some Create operations return a list of the
+// resources created, e.g. CreateLoadBalancer in the AWS ELBv2 API.
+func (a *API) outputIdentifiers(op *Operation, resourceName string) []string {
+	res := []string{}
+	if op == nil || op.Output == nil {
+		return res
+	}
+	outShapeName := op.Output.ShapeName
+	res = append(res, a.shapeIdentifiers(outShapeName, resourceName, false)...)
+	outShape := a.apiSpec.Shapes[outShapeName]
+	if outShape == nil {
+		return res
+	}
+	memberNames := make([]string, 0, len(outShape.Members))
+	for memberName := range outShape.Members {
+		memberNames = append(memberNames, memberName)
+	}
+	sort.Strings(memberNames)
+	for _, memberName := range memberNames {
+		ref := outShape.Members[memberName]
+		isList := a.isListShape(ref)
+		if isList {
+			ref = a.apiSpec.Shapes[*ref.ShapeName].ListMember
+		}
+		if ref == nil || ref.ShapeName == nil {
+			continue
+		}
+		shape, found := a.apiSpec.Shapes[*ref.ShapeName]
+		if !found || shape.Type != "structure" {
+			continue
+		}
+		// Members like "id" or "arn" in a list of structures only identify
+		// the resource if the list is of the resources, e.g. the
+		// LoadBalancers member of the CreateLoadBalancerOutput shape in the
+		// AWS ELBv2 API, and not, say, of the asynchronous operations
+		// creating them
+		describesResource := !isList || sameName(memberName, resourceName) ||
+			sameName(*ref.ShapeName, resourceName)
+		for _, id := range a.shapeIdentifiers(*ref.ShapeName, resourceName, false) {
+			if splitIdentifier(id, resourceName).prefix == "" && !describesResource {
+				continue
+			}
+			if !inStrings(id, res) {
+				res = append(res, id)
+			}
+		}
+	}
+	sortIdentifiers(res, resourceName)
+	return res
+}
+
+// sameName returns true if the supplied shape or member name is the name of
+// the resource, ignoring case and the plural, e.g. "DBInstance" or
+// "LoadBalancers"
+func sameName(name string, resourceName string) bool {
+	return strings.EqualFold(name, resourceName) ||
+		strings.EqualFold(name, pluralName(resourceName))
+}
+
+// inputIdentifier returns the name of the member of the operation's input
+// shape that identifies the named resource, preferring one of the supplied
+// identifiers and members identifying a single resource, or the empty string
+// if there is no such member
+func (a *API) inputIdentifier(op *Operation, identifiers []string, resourceName string) string {
+	if op == nil || op.Input == nil {
+		return ""
+	}
+	inIdentifiers := a.shapeIdentifiers(op.Input.ShapeName, resourceName, false)
+	if len(inIdentifiers) == 0 {
+		inIdentifiers = a.listIdentifiers(op.Input.ShapeName, resourceName)
+	}
+	for _, memberName := range inIdentifiers {
+		if matchIdentifier(memberName, identifiers, resourceName) != "" {
+			return memberName
+		}
+	}
+	if len(inIdentifiers) > 0 {
+		return inIdentifiers[0]
+	}
+	return ""
+}
@@ -0,0 +1,315 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"strings"
+	"testing"
+)
+
+const apigatewayModel = `{
+  "metadata": {
+    "apiVersion": "2015-07-09",
+    "endpointPrefix": "apigateway",
+    "protocol": "rest-json",
+    "serviceFullName": "Amazon API Gateway",
+    "serviceId": "API Gateway",
+    "signatureVersion": "v4"
+  },
+  "operations": {
+    "CreateRestApi": {
+      "name": "CreateRestApi",
+      "http": {"method": "POST", "requestUri": "/restapis", "responseCode": 201},
+      "input": {"shape": "CreateRestApiRequest"},
+      "output": {"shape": "RestApi"}
+    },
+    "GetRestApi": {
+      "name": "GetRestApi",
+      "http": {"method": "GET", "requestUri": "/restapis/{restapi_id}"},
+      "input": {"shape": "GetRestApiRequest"},
+      "output": {"shape": "RestApi"}
+    },
+    "DeleteRestApi": {
+      "name": "DeleteRestApi",
+      "http": {"method": "DELETE", "requestUri": "/restapis/{restapi_id}", "responseCode": 202},
+      "input": {"shape": "DeleteRestApiRequest"}
+    },
+    "CreateDeployment": {
+      "name": "CreateDeployment",
+      "http": {"method": "POST", "requestUri": "/restapis/{restapi_id}/deployments", "responseCode": 201},
+      "input": {"shape": "CreateDeploymentRequest"},
+      "output": {"shape": "Deployment"}
+    },
+    "GetDeployment": {
+      "name": "GetDeployment",
+      "http": {"method": "GET", "requestUri": "/restapis/{restapi_id}/deployments/{deployment_id}"},
+      "input": {"shape": "GetDeploymentRequest"},
+      "output": {"shape": "Deployment"}
+    },
+    "CreateStage": {
+      "name": "CreateStage",
+      "http": {"method": "POST", "requestUri": "/restapis/{restapi_id}/stages", "responseCode": 201},
+      "input": {"shape": "CreateStageRequest"},
+      "output": {"shape": "Stage"}
+    },
+    "CreateResource": {
+      "name": "CreateResource",
+      "http": {"method": "POST", "requestUri": "/restapis/{restapi_id}/resources/{parent_id}", "responseCode": 201},
+      "input": {"shape": "CreateResourceRequest"},
+      "output": {"shape": "Resource"}
+    }
+  },
+  "shapes": {
+    "CreateRestApiRequest": {
+      "type": "structure",
+      "required": ["name"],
+      "members": {"name": {"shape": "String"}}
+    },
+    "GetRestApiRequest": {
+      "type": "structure",
+      "required": ["restApiId"],
+      "members": {
+        "restApiId": {"shape": "String", "location": "uri", "locationName": "restapi_id"}
+      }
+    },
+    "DeleteRestApiRequest": {
+      "type": "structure",
+      "required": ["restApiId"],
+      "members": {
+        "restApiId": {"shape": "String", "location": "uri", "locationName": "restapi_id"}
+      }
+    },
+    "RestApi": {
+      "type": "structure",
+      "members": {
+        "id": {"shape": "String"},
+        "name": {"shape": "String"}
+      }
+    },
+    "CreateDeploymentRequest": {
+      "type": "structure",
+      "required": ["restApiId"],
+      "members": {
+        "restApiId": {"shape": "String", "location": "uri", "locationName": "restapi_id"},
+        "stageName": {"shape": "String"}
+      }
+    },
+    "GetDeploymentRequest": {
+      "type": "structure",
+      "required": ["restApiId", "deploymentId"],
+      "members": {
+        "restApiId": {"shape": "String", "location": "uri", "locationName": "restapi_id"},
+        "deploymentId": {"shape": "String", "location": "uri", "locationName": "deployment_id"}
+      }
+    },
+    "Deployment": {
+      "type": "structure",
+      "members": {
+        "id": {"shape": "String"},
+        "description": {"shape": "String"}
+      }
+    },
+    "CreateStageRequest": {
+      "type": "structure",
+      "required": ["restApiId", "stageName", "deploymentId"],
+      "members": {
+        "restApiId": {"shape": "String", "location": "uri", "locationName": "restapi_id"},
+        "stageName": {"shape": "String"},
+        "deploymentId": {"shape": "String"}
+      }
+    },
+    "Stage": {
+      "type": "structure",
+      "members": {
+        "deploymentId": {"shape": "String"},
+        "stageName": {"shape": "String"}
+      }
+    },
+    "CreateResourceRequest": {
+      "type": "structure",
+      "required": ["restApiId", "parentId", "pathPart"],
+      "members": {
+        "restApiId": {"shape": "String", "location": "uri", "locationName": "restapi_id"},
+        "parentId": {"shape": "String", "location": "uri", "locationName": "parent_id"},
+        "pathPart": {"shape": "String"}
+      }
+    },
+    "Resource": {
+      "type": "structure",
+      "members": {
+        "id": {"shape": "String"},
+        "parentId": {"shape": "String"},
+        "pathPart": {"shape": "String"}
+      }
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+// widgetModel is a model whose Delete operation identifies a Widget by the
+// name the caller supplies to the Create operation, which only returns an ID
+const widgetModel = `{
+  "metadata": {
+    "apiVersion": "2020-01-01",
+    "endpointPrefix": "widgets",
+    "jsonVersion": "1.1",
+    "protocol": "json",
+    "serviceFullName": "Widget Service",
+    "serviceId": "Widgets",
+    "signatureVersion": "v4",
+    "targetPrefix": "Widgets"
+  },
+  "operations": {
+    "CreateWidget": {
+      "name": "CreateWidget",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateWidgetRequest"},
+      "output": {"shape": "CreateWidgetResponse"}
+    },
+    "DeleteWidget": {
+      "name": "DeleteWidget",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DeleteWidgetRequest"}
+    }
+  },
+  "shapes": {
+    "CreateWidgetRequest": {
+      "type": "structure",
+      "required": ["WidgetName"],
+      "members": {"WidgetName": {"shape": "String"}}
+    },
+    "CreateWidgetResponse": {
+      "type": "structure",
+      "members": {"WidgetId": {"shape": "String"}}
+    },
+    "DeleteWidgetRequest": {
+      "type": "structure",
+      "required": ["WidgetName"],
+      "members": {"WidgetName": {"shape": "String"}}
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		memberName   string
+		resourceName string
+		prefix       string
+		kind         string
+		identifies   bool
+	}{
+		{"TopicArn", "Topic", "Topic", IdentifierKindARN, true},
+		{"id", "RestApi", "", IdentifierKindID, true},
+		{"restApiId", "RestApi", "restApi", IdentifierKindID, true},
+		{"GroupId", "SecurityGroup", "Group", IdentifierKindID, true},
+		{"GroupIds", "SecurityGroup", "Group", IdentifierKindID, true},
+		{"DBInstanceIdentifier", "DBInstance", "DBInstance", IdentifierKindID, true},
+		{"QueueUrl", "Queue", "Queue", IdentifierKindURL, true},
+		{"Bucket", "Bucket", "", IdentifierKindName, true},
+		{"domainName", "DomainName", "", IdentifierKindName, true},
+		// A prefix must end the resource name at a word boundary
+		{"upId", "Group", "up", IdentifierKindID, false},
+		{"VpcId", "Subnet", "Vpc", IdentifierKindID, false},
+		{"CidrBlock", "Vpc", "", "", false},
+	}
+	for _, test := range tests {
+		got := splitIdentifier(test.memberName, test.resourceName)
+		if got.prefix != test.prefix || got.kind != test.kind {
+			t.Errorf("%s of %s: expected prefix %q and kind %q, got %q and %q",
+				test.memberName, test.resourceName, test.prefix, test.kind, got.prefix, got.kind)
+		}
+		if identifies := got.identifies(test.resourceName); identifies != test.identifies {
+			t.Errorf("%s of %s: expected identifies %v, got %v",
+				test.memberName, test.resourceName, test.identifies, identifies)
+		}
+	}
+}
+
+func TestSameIdentifier(t *testing.T) {
+	tests := []struct {
+		a            string
+		b            string
+		resourceName string
+		same         bool
+	}{
+		{"id", "restApiId", "RestApi", true},
+		{"VpcId", "VpcId", "Vpc", true},
+		{"GroupId", "GroupName", "SecurityGroup", false},
+		// DeploymentId does not identify a RestApi
+		{"id", "deploymentId", "RestApi", false},
+	}
+	for _, test := range tests {
+		if same := sameIdentifier(test.a, test.b, test.resourceName); same != test.same {
+			t.Errorf("%s and %s of %s: expected %v, got %v", test.a, test.b, test.resourceName, test.same, same)
+		}
+	}
+}
+
+func TestResourceIdentifiers(t *testing.T) {
+	tests := []struct {
+		serviceAlias      string
+		modelJSON         string
+		resource          string
+		identifiers       string
+		primaryIdentifier string
+		readOneIdentifier string
+		deleteIdentifier  string
+		mismatches        int
+	}{
+		// The ReadOne and Delete operations take a restApiId, which is the
+		// id returned by CreateRestApi
+		{"apigateway", apigatewayModel, "RestApi", "id,name", "id", "restApiId", "restApiId", 0},
+		{"apigateway", apigatewayModel, "Deployment", "id", "id", "deploymentId", "", 0},
+		// The Vpc returned by CreateVpc identifies the Vpc
+		{"ec2", ec2Model, "Vpc", "VpcId", "VpcId", "VpcIds", "VpcId", 0},
+		{"widgets", widgetModel, "Widget", "WidgetId", "WidgetId", "", "WidgetName", 1},
+	}
+	for _, test := range tests {
+		api := newTestAPI(t, test.serviceAlias, test.modelJSON)
+		r := api.GetResource(test.resource)
+		if r == nil {
+			t.Fatalf("%s: expected resource %s", test.serviceAlias, test.resource)
+		}
+		got := []string{
+			strings.Join(r.Identifiers, ","), r.PrimaryIdentifier,
+			r.ReadOneIdentifier, r.DeleteIdentifier,
+		}
+		want := []string{
+			test.identifiers, test.primaryIdentifier,
+			test.readOneIdentifier, test.deleteIdentifier,
+		}
+		for x := range want {
+			if got[x] != want[x] {
+				t.Errorf("%s: expected Identifiers, PrimaryIdentifier, ReadOneIdentifier, DeleteIdentifier %q, got %q", test.resource, want, got)
+				break
+			}
+		}
+		if mismatches := r.IdentifierMismatches(); len(mismatches) != test.mismatches {
+			t.Errorf("%s: expected %d identifier mismatches, got %d", test.resource, test.mismatches, len(mismatches))
+		}
+	}
+}
+
+func TestIdentifierMismatchSupplied(t *testing.T) {
+	api := newTestAPI(t, "widgets", widgetModel)
+	mismatches := api.GetResource("Widget").IdentifierMismatches()
+	if len(mismatches) != 1 {
+		t.Fatalf("expected a single identifier mismatch, got %d", len(mismatches))
+	}
+	got := mismatches[0]
+	want := IdentifierMismatch{
+		OperationType: OperationTypeDelete,
+		Operation:     "DeleteWidget",
+		Identifier:    "WidgetName",
+		// The caller chooses the WidgetName when creating the Widget
+		Supplied: true,
+	}
+	if *got != want {
+		t.Errorf("expected mismatch %+v, got %+v", want, *got)
+	}
+}
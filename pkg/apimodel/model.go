@@ -7,7 +7,9 @@
 package apimodel
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	sdkmodelapi "github.com/aws/aws-sdk-go/private/model/api"
@@ -22,6 +24,7 @@ const (
 	ObjectTypePayload   = "payload"
 	ObjectTypeException = "exception"
 	ObjectTypeList      = "list"
+	ObjectTypeMap       = "map"
 )
 
 type Object struct {
@@ -33,6 +36,22 @@ type Object struct {
 type Operation struct {
 	Name   string
 	Method string
+	// Paginated is true if the operation returns its results in pages
+	Paginated bool
+	Input     *ShapeRef
+	Output    *ShapeRef
+	Errors    []*ShapeRef
+	// AuthType overrides how requests to the operation are signed, e.g.
+	// "none" for operations that may be called anonymously or
+	// "v4-unsigned-body" for operations with an unsigned payload
+	AuthType string
+	// HostPrefix is prepended to the endpoint host of the operation and may
+	// contain {Member} labels filled from the input's HostLabel members
+	HostPrefix           string
+	HTTPChecksumRequired bool
+	Deprecated           bool
+	DeprecatedMessage    string
+	DocumentationURL     string
 }
 
 type API struct {
@@ -40,41 +59,160 @@ type API struct {
 	AliasLower string
 	// And this is the sometimes-titlecased alias from the metadata.json file
 	// in the aws-sdk-go/services/$alias_lower/$version directory
-	Alias     string
-	FullName  string
-	Protocol  string
-	Version   string
-	apiSpec   *apiSpec
-	docSpec   *docSpec
-	objectMap map[string]*Object
-	swagger   *oai.Swagger
-	sdkAPI    *sdkmodelapi.API
+	Alias    string
+	FullName string
+	Protocol string
+	Version  string
+	apiSpec  *apiSpec
+	docSpec  *docSpec
+	// endpointsDoc is the contents of the endpoints file, if the model
+	// source has one
+	endpointsDoc []byte
+	objectMap    map[string]*Object
+	swagger      *oai.Swagger
+	// schemaOpts are the options the swagger was evaluated with
+	schemaOpts SchemaOptions
 }
 
+// SchemaOptions control how an API is described by its OpenAPI schema
+type SchemaOptions struct {
+	// RPCStyle is how the operations of RPC-style (json and query protocol)
+	// APIs are represented. See RPCStyles.
+	RPCStyle string
+	// DocFormat is the format of the descriptions converted from the
+	// documentation of the API, its operations, shapes and members. See
+	// DocFormats.
+	DocFormat string
+}
+
+// DefaultSchemaOptions returns the SchemaOptions used by API.Schema
+func DefaultSchemaOptions() *SchemaOptions {
+	return &SchemaOptions{
+		RPCStyle:  RPCStyleFragment,
+		DocFormat: DocFormatMarkdown,
+	}
+}
+
+// New returns a new API object describing the newest API version of the
+// supplied service alias, with model and doc files read from the SDKHelper's
+// ModelSource
 func New(serviceAlias string, sdkHelper *model.SDKHelper) (*API, error) {
-	modelPath, docPath, err := sdkHelper.ModelAndDocsPath(serviceAlias)
+	return NewWithVersion(serviceAlias, "", sdkHelper)
+}
+
+// NewWithVersion returns a new API object describing the supplied API version
+// of a service alias. If apiVersion is empty, the newest API version is used.
+func NewWithVersion(
+	serviceAlias string,
+	apiVersion string,
+	sdkHelper *model.SDKHelper,
+) (*API, error) {
+	modelBytes, docBytes, err := sdkHelper.ModelAndDocs(serviceAlias, apiVersion)
 	if err != nil {
 		return nil, err
 	}
-	apiSpec, docSpec, err := parseFrom(modelPath, docPath)
+	apiSpec, docSpec, err := parseFrom(modelBytes, docBytes)
 	if err != nil {
+		return nil, fmt.Errorf("failed to parse API %s: %v", serviceAlias, err)
+	}
+	backfillAuthTypes(serviceAlias, apiSpec)
+	paginatorBytes, err := sdkHelper.ReadFile(serviceAlias, apiVersion, model.PaginatorsFile)
+	if err == nil {
+		if apiSpec.Paginators, err = parsePaginators(paginatorBytes); err != nil {
+			return nil, fmt.Errorf("failed to parse API %s: %v", serviceAlias, err)
+		}
+	} else if !errors.Is(err, model.ErrModelFileNotFound) {
 		return nil, err
 	}
-	sdkAPI, err := sdkHelper.API(serviceAlias)
-	if err != nil {
+	waiterBytes, err := sdkHelper.ReadFile(serviceAlias, apiVersion, model.WaitersFile)
+	if err == nil {
+		if apiSpec.Waiters, err = parseWaiters(waiterBytes); err != nil {
+			return nil, fmt.Errorf("failed to parse API %s: %v", serviceAlias, err)
+		}
+	} else if !errors.Is(err, model.ErrModelFileNotFound) {
 		return nil, err
 	}
+	exampleBytes, err := sdkHelper.ReadFile(serviceAlias, apiVersion, model.ExamplesFile)
+	if err == nil {
+		if apiSpec.Examples, err = parseExamples(exampleBytes); err != nil {
+			return nil, fmt.Errorf("failed to parse API %s: %v", serviceAlias, err)
+		}
+	} else if !errors.Is(err, model.ErrModelFileNotFound) {
+		return nil, err
+	}
+	endpointsDoc, err := sdkHelper.Endpoints()
+	if err != nil && !errors.Is(err, model.ErrModelFileNotFound) {
+		return nil, err
+	}
+	meta := apiSpec.Metadata
+	// Use the same alias normalization as the aws-sdk-go code generator
+	alias := sdkmodelapi.ServiceID(&sdkmodelapi.API{
+		Metadata: sdkmodelapi.Metadata{
+			ServiceID:           meta.Alias,
+			ServiceAbbreviation: meta.Abbreviation,
+			ServiceFullName:     meta.FullName,
+		},
+	})
 	return &API{
-		Alias:    sdkmodelapi.ServiceID(sdkAPI),
-		FullName: sdkAPI.Metadata.ServiceFullName,
-		Version:  sdkAPI.Metadata.APIVersion,
-		Protocol: sdkAPI.Metadata.Protocol,
-		apiSpec:  apiSpec,
-		docSpec:  docSpec,
-		sdkAPI:   sdkAPI,
+		AliasLower:   serviceAlias,
+		Alias:        alias,
+		FullName:     meta.FullName,
+		Version:      meta.APIVersion,
+		Protocol:     meta.Protocol,
+		apiSpec:      apiSpec,
+		docSpec:      docSpec,
+		endpointsDoc: endpointsDoc,
 	}, nil
 }
 
+// Summary contains an API's identifying information and the number of
+// operations and objects of each type in the API
+type Summary struct {
+	AliasLower string `json:"alias_lower"`
+	Alias      string `json:"alias"`
+	FullName   string `json:"full_name"`
+	Protocol   string `json:"protocol"`
+	Version    string `json:"version"`
+	Operations int    `json:"operations"`
+	Resources  int    `json:"resources"`
+	Objects    int    `json:"objects"`
+	Scalars    int    `json:"scalars"`
+	Payloads   int    `json:"payloads"`
+	Exceptions int    `json:"exceptions"`
+	Lists      int    `json:"lists"`
+	Maps       int    `json:"maps"`
+}
+
+// Summary returns a Summary of the API
+func (a *API) Summary() *Summary {
+	objects := a.GetObjects(nil)
+	s := &Summary{
+		AliasLower: a.AliasLower,
+		Alias:      a.Alias,
+		FullName:   a.FullName,
+		Protocol:   a.Protocol,
+		Version:    a.Version,
+		Operations: len(a.GetOperations(nil)),
+		Resources:  len(a.GetTopLevelResources()),
+		Objects:    len(objects),
+	}
+	for _, obj := range objects {
+		switch obj.Type {
+		case ObjectTypeScalar:
+			s.Scalars++
+		case ObjectTypeList:
+			s.Lists++
+		case ObjectTypeMap:
+			s.Maps++
+		case ObjectTypePayload:
+			s.Payloads++
+		case ObjectTypeException:
+			s.Exceptions++
+		}
+	}
+	return s
+}
+
 type OperationFilter struct {
 	Methods  []string
 	Prefixes []string
@@ -92,23 +230,63 @@ func (a *API) GetOperations(filter *OperationFilter) []*Operation {
 	if filter != nil {
 		filterMethods = filter.Methods
 	}
-	for _, sdkOp := range a.sdkAPI.OperationList() {
-		if sdkOp.HTTP.Method == "" {
+	opNames := make([]string, 0, len(a.apiSpec.Operations))
+	for opName := range a.apiSpec.Operations {
+		opNames = append(opNames, opName)
+	}
+	sort.Strings(opNames)
+	for _, opName := range opNames {
+		opSpec := a.apiSpec.Operations[opName]
+		if opSpec.HTTP == nil || opSpec.HTTP.Method == "" {
 			continue
 		}
-		meth := sdkOp.HTTP.Method
+		meth := opSpec.HTTP.Method
 		// Match on any of the supplied prefixes
-		if len(filterPrefixes) > 0 && !hasAnyPrefix(sdkOp.Name, filterPrefixes) {
+		if len(filterPrefixes) > 0 && !hasAnyPrefix(opName, filterPrefixes) {
 			continue
 		}
 		if len(filterMethods) > 0 && !inStrings(meth, filterMethods) {
 			continue
 		}
-		res = append(res, &Operation{Name: sdkOp.Name, Method: meth})
+		res = append(res, a.newOperation(opName, opSpec))
 	}
 	return res
 }
 
+// GetOperation returns the operation with the supplied name or nil if the API
+// has no such operation
+func (a *API) GetOperation(opName string) *Operation {
+	opSpec, found := a.apiSpec.Operations[opName]
+	if !found || opSpec.HTTP == nil || opSpec.HTTP.Method == "" {
+		return nil
+	}
+	return a.newOperation(opName, opSpec)
+}
+
+func (a *API) newOperation(opName string, opSpec *opSpec) *Operation {
+	op := &Operation{
+		Name:                 opName,
+		Method:               opSpec.HTTP.Method,
+		Input:                newShapeRef(opSpec.Input),
+		Output:               newShapeRef(opSpec.Output),
+		AuthType:             opSpec.AuthType,
+		HTTPChecksumRequired: opSpec.HTTPChecksumRequired,
+		Deprecated:           opSpec.Deprecated,
+		DeprecatedMessage:    opSpec.DeprecatedMessage,
+		DocumentationURL:     opSpec.DocumentationURL,
+	}
+	if pag := a.GetPaginator(opName); pag != nil {
+		op.Paginated = pag.IsPaginated()
+	}
+	for _, errRef := range opSpec.Errors {
+		op.Errors = append(op.Errors, newShapeRef(errRef))
+	}
+	if opSpec.Endpoint != nil {
+		op.HostPrefix = opSpec.Endpoint.HostPrefix
+	}
+	return op
+}
+
 type ObjectFilter struct {
 	Types    []string
 	Prefixes []string
@@ -116,7 +294,7 @@ type ObjectFilter struct {
 
 // GetObjects returns objects that match any of the supplied filter
 func (a *API) GetObjects(filter *ObjectFilter) []*Object {
-	if err := a.eval(); err != nil {
+	if err := a.eval(nil); err != nil {
 		fmt.Printf("ERROR evaluating API: %v\n", err)
 		return nil
 	}
@@ -141,23 +319,41 @@ func (a *API) GetObjects(filter *ObjectFilter) []*Object {
 	return res
 }
 
+// Schema returns the OpenAPI schema describing the API, using the default
+// SchemaOptions
 func (a *API) Schema() *oai.Swagger {
-	if err := a.eval(); err != nil {
+	swagger, err := a.SchemaWithOptions(DefaultSchemaOptions())
+	if err != nil {
 		fmt.Printf("ERROR evaluating API: %v\n", err)
 		return nil
 	}
+	return swagger
+}
+
+// SchemaWithOptions returns the OpenAPI schema describing the API, using the
+// supplied SchemaOptions
+func (a *API) SchemaWithOptions(opts *SchemaOptions) (*oai.Swagger, error) {
+	if !inStrings(opts.RPCStyle, RPCStyles) {
+		return nil, fmt.Errorf("unknown RPC style %q", opts.RPCStyle)
+	}
+	if !inStrings(opts.DocFormat, DocFormats) {
+		return nil, fmt.Errorf("unknown doc format %q", opts.DocFormat)
+	}
+	if err := a.eval(opts); err != nil {
+		return nil, err
+	}
 	info := &oai.Info{
 		Title:       a.FullName,
 		Version:     a.Version,
-		Description: a.docSpec.Service,
+		Description: formatDoc(a.docSpec.Service, opts.DocFormat),
 	}
 	exts := map[string]interface{}{}
-	info.ExtensionProps = oai.ExtensionProps{exts}
+	info.ExtensionProps = oai.ExtensionProps{Extensions: exts}
 	info.ExtensionProps.Extensions["x-aws-api-alias"] = a.Alias
 	info.ExtensionProps.Extensions["x-aws-api-protocol"] = a.Protocol
 	a.swagger.Info = info
 	a.swagger.OpenAPI = "3.0.0"
-	return a.swagger
+	return a.swagger, nil
 }
 
 func inStrings(subject string, collection []string) bool {
@@ -0,0 +1,66 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+// testAPIVersion is the API version of the models built by newTestAPI
+const testAPIVersion = "2020-01-01"
+
+// newTestAPI returns the API described by the supplied api-2.json contents,
+// read from a MemorySource under the supplied service alias
+func newTestAPI(t *testing.T, serviceAlias string, modelJSON string) *API {
+	t.Helper()
+	return newTestAPIFromFiles(t, serviceAlias, map[string]string{model.ModelFile: modelJSON})
+}
+
+// newTestAPIFromFiles returns the API described by the supplied model files,
+// e.g. api-2.json and paginators-1.json, keyed by file name
+func newTestAPIFromFiles(t *testing.T, serviceAlias string, files map[string]string) *API {
+	t.Helper()
+	src := model.NewMemorySource()
+	for fileName, contents := range files {
+		src.Add(serviceAlias, testAPIVersion, fileName, []byte(contents))
+	}
+	api, err := New(serviceAlias, model.NewSDKHelperFromSource(src))
+	if err != nil {
+		t.Fatalf("failed to load test API %s: %v", serviceAlias, err)
+	}
+	return api
+}
+
+// testSchema returns the OpenAPI schema of the supplied API, using the
+// default SchemaOptions
+func testSchema(t *testing.T, api *API) *oai.Swagger {
+	t.Helper()
+	swagger, err := api.SchemaWithOptions(DefaultSchemaOptions())
+	if err != nil {
+		t.Fatalf("failed to evaluate test API %s: %v", api.AliasLower, err)
+	}
+	return swagger
+}
+
+// findOperation returns the path and OpenAPI operation with the supplied
+// operation ID, failing the test if there is no such operation
+func findOperation(t *testing.T, swagger *oai.Swagger, operationID string) (string, *oai.Operation) {
+	t.Helper()
+	for path, pathItem := range swagger.Paths {
+		for _, op := range pathItem.Operations() {
+			if op.OperationID == operationID {
+				return path, op
+			}
+		}
+	}
+	t.Fatalf("no operation %s in schema", operationID)
+	return "", nil
+}
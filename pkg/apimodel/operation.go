@@ -16,18 +16,71 @@ func (opSpec *opSpec) Operation(opName string, doc string, api *oai.Swagger, api
 	op := oai.NewOperation()
 	op.OperationID = opName
 	op.Description = doc
+	meta := &apiSpec.Metadata
 
-	// Find the shape representing the input to the create operation and
-	// add a pointer to an oai.Schema describing the input shape
-	if opSpec.Input != nil {
+	meta.applySecurity(op, opSpec.AuthType)
+	// JSON protocol requests carry the operation name in the X-Amz-Target
+	// header
+	if target := meta.target(opName); target != "" {
+		op.Parameters = append(op.Parameters, targetParameter(target))
+	}
+	// Query protocol requests are form-encoded and carry the operation name
+	// and API version alongside the members of the input shape
+	if meta.isQuery() {
+		if opSpec.Input != nil {
+			if _, found := api.Components.Schemas[*opSpec.Input.ShapeName]; !found {
+				return nil, fmt.Errorf("expected to find input shape schema ref %s", *opSpec.Input.ShapeName)
+			}
+		}
+		reqBody := queryRequestBody(opName, opSpec.Input, meta)
+		addExamples(reqBody.Content.Get(mediaTypeForm), opName, apiSpec.Examples[opName], false)
+		op.RequestBody = &oai.RequestBodyRef{Value: reqBody}
+	}
+	// Find the shape representing the input to the create operation. Members
+	// of the input shape bound to the URI, query string or headers become
+	// parameters and the remaining members make up the request body
+	if opSpec.Input != nil && !meta.isQuery() {
 		inShapeName := *opSpec.Input.ShapeName
 		_, found := api.Components.Schemas[inShapeName]
 		if !found {
 			return nil, fmt.Errorf("expected to find input shape schema ref %s", inShapeName)
 		}
-		inShapeSchemaRef := oai.NewSchemaRef("#/components/schemas/"+inShapeName, nil)
-		reqBody := oai.NewRequestBody().WithJSONSchemaRef(inShapeSchemaRef)
-		op.RequestBody = &oai.RequestBodyRef{Value: reqBody}
+		inShape, found := apiSpec.Shapes[inShapeName]
+		if !found {
+			return nil, fmt.Errorf("expected to find input shape %s", inShapeName)
+		}
+		params, prefixHeaders := bindingParameters(inShape)
+		op.Parameters = append(op.Parameters, params...)
+		if len(prefixHeaders) > 0 {
+			addExtension(&op.ExtensionProps, "x-aws-prefix-headers", prefixHeaders)
+		}
+		omitEmpty := inStrings(opSpec.HTTP.Method, methodsWithoutBody)
+		reqFormat := &bodyFormat{mediaType: meta.requestMediaType()}
+		if meta.isXML() {
+			reqFormat.root = meta.rootXML(opSpec.Input, inShape)
+		}
+		content, err := bodyContent(inShapeName, inShape, apiSpec, reqFormat, omitEmpty)
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			reqBody := oai.NewRequestBody().WithContent(content)
+			addExamples(content.Get(reqFormat.mediaType), opName, apiSpec.Examples[opName], false)
+			op.RequestBody = &oai.RequestBodyRef{Value: reqBody}
+		}
+	}
+	successRespCode := 200
+	if opSpec.HTTP.ResponseCode != nil {
+		successRespCode = *opSpec.HTTP.ResponseCode
+	}
+	// Query protocol responses are XML envelopes that contain the request ID
+	// even when the operation has no output
+	if opSpec.Output == nil {
+		resp := oai.NewResponse()
+		if meta.isQuery() {
+			resp.WithContent(queryResponseContent(opName, nil, meta))
+		}
+		op.AddResponse(successRespCode, resp)
 	}
 	// Find the shape representing the ouput of the create operation and
 	// add fields from the output shape to the resource object, excluding
@@ -38,66 +91,50 @@ func (opSpec *opSpec) Operation(opName string, doc string, api *oai.Swagger, api
 		if !found {
 			return nil, fmt.Errorf("expected to find output shape schema ref %s", outShapeName)
 		}
-		successRespCode := 200
-		if opSpec.HTTP.ResponseCode != nil {
-			successRespCode = *opSpec.HTTP.ResponseCode
+		outShape, found := apiSpec.Shapes[outShapeName]
+		if !found {
+			return nil, fmt.Errorf("expected to find output shape %s", outShapeName)
 		}
-		outShapeSchemaRef := oai.NewSchemaRef("#/components/schemas/"+outShapeName, nil)
-		op.AddResponse(successRespCode, oai.NewResponse().WithJSONSchemaRef(outShapeSchemaRef))
-
-		if len(opSpec.Errors) > 0 {
-			// Due to the way OpenAPI3 Operations are structured, we need to
-			// first gather all errors that occur with the same HTTP response
-			// code and then use an oneOf JSONSchema to inject each of the
-			// possible error responses into the Operation
-			codeSchemaMap := map[int][]*oai.SchemaRef{}
-			for _, errShapeSpec := range opSpec.Errors {
-				if errShapeSpec.ShapeName == nil {
-					return nil, fmt.Errorf("expected to find error shape name but found %v", errShapeSpec)
-				}
-				errShapeName := *errShapeSpec.ShapeName
-				errShape, found := apiSpec.Shapes[errShapeName]
-				if !found {
-					return nil, fmt.Errorf("expected to find error shape %s", errShapeName)
-				}
-				errRespCode := 400
-				errShapeSchemaRef := oai.NewSchemaRef("#/components/schemas/"+errShapeName, nil)
-				if errShape.Error == nil {
-					// Some older XML APIs like S3 do not have an Error field
-					// in the shape spec. Instead, the shape spec will have no
-					// members, no HTTP response code, nothing but the name of
-					// the error.
-					//
-					// For example:
-					//
-					// "NoSuchKey":{
-					//   "type":"structure",
-					//   "members":{
-					//   },
-					//   "exception":true
-					// },
-					//
-					// In these cases, we just need to use a generic 400 HTTP
-					// status code (even though many are actually 404s)
-				} else if errShape.Error.HTTPStatusCode != nil {
-					errRespCode = *errShape.Error.HTTPStatusCode
-				}
-				schemasWithCode, exists := codeSchemaMap[errRespCode]
-				if !exists {
-					schemasWithCode = []*oai.SchemaRef{}
-					codeSchemaMap[errRespCode] = schemasWithCode
-				}
-				codeSchemaMap[errRespCode] = append(codeSchemaMap[errRespCode], errShapeSchemaRef)
+		resp := oai.NewResponse()
+		headers, prefixHeaders := bindingHeaders(outShape)
+		if len(headers) > 0 {
+			resp.Headers = headers
+		}
+		if len(prefixHeaders) > 0 {
+			addExtension(&resp.ExtensionProps, "x-aws-prefix-headers", prefixHeaders)
+		}
+		respFormat := &bodyFormat{mediaType: meta.responseMediaType()}
+		if meta.Protocol == ProtocolRESTXML {
+			respFormat.root = meta.rootXML(opSpec.Output, outShape)
+		}
+		var content oai.Content
+		if meta.isQuery() {
+			content = queryResponseContent(opName, opSpec.Output, meta)
+		} else {
+			var err error
+			content, err = bodyContent(outShapeName, outShape, apiSpec, respFormat, false)
+			if err != nil {
+				return nil, err
 			}
-			for errRespCode, schemaRefs := range codeSchemaMap {
-				if len(schemaRefs) > 1 {
-					respSchema := &oai.Schema{OneOf: schemaRefs}
-					op.AddResponse(errRespCode, oai.NewResponse().WithJSONSchema(respSchema))
-				} else {
-					op.AddResponse(errRespCode, oai.NewResponse().WithJSONSchemaRef(schemaRefs[0]))
-				}
+		}
+		if content != nil {
+			resp.WithContent(content)
+			addExamples(content.Get(respFormat.mediaType), opName, apiSpec.Examples[opName], true)
+			if meta.isQuery() {
+				wrapQueryExamples(content.Get(respFormat.mediaType), opName, opSpec.Output, meta)
 			}
 		}
+		op.AddResponse(successRespCode, resp)
+	}
+	// Errors are returned whether or not the operation has an output, and
+	// any operation may return errors it does not model, e.g. for throttling
+	errResps, err := errorResponses(opSpec.Errors, apiSpec)
+	if err != nil {
+		return nil, err
+	}
+	for errRespCode, errResp := range errResps {
+		op.AddResponse(errRespCode, errResp)
 	}
+	op.Responses["default"] = defaultErrorResponseRef(api)
 	return op, nil
 }
@@ -0,0 +1,138 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// stringOrList is a JSON value that may be either a single string or a list
+// of strings, as used by several fields in paginators-1.json
+type stringOrList []string
+
+func (s *stringOrList) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*s = stringOrList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*s = stringOrList(list)
+	return nil
+}
+
+type paginatorSpec struct {
+	InputToken  stringOrList `json:"input_token"`
+	OutputToken stringOrList `json:"output_token"`
+	LimitKey    string       `json:"limit_key"`
+	MoreResults string       `json:"more_results"`
+	ResultKey   stringOrList `json:"result_key"`
+}
+
+type paginatorsSpec struct {
+	Pagination map[string]*paginatorSpec `json:"pagination"`
+}
+
+// parsePaginators returns the paginator definitions, keyed by operation name,
+// in the supplied paginators-1.json document
+func parsePaginators(b []byte) (map[string]*paginatorSpec, error) {
+	var spec paginatorsSpec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode paginators: %v", err)
+	}
+	return spec.Pagination, nil
+}
+
+// Paginator describes how the results of an operation are split into pages.
+// The tokens and result keys are JMESPath-like expressions against the
+// operation's input and output shapes, usually just member names.
+type Paginator struct {
+	// Operation is the name of the paginated operation
+	Operation string
+	// InputTokens are the input members that take the token(s) returned in
+	// OutputTokens in order to fetch the next page
+	InputTokens []string
+	// OutputTokens are the output members containing the token(s) for
+	// fetching the next page
+	OutputTokens []string
+	// LimitKey is the input member that limits the number of results per
+	// page, if any
+	LimitKey string
+	// MoreResults is the output member indicating whether there are more
+	// pages, if any
+	MoreResults string
+	// ResultKeys are the output members containing the paged results
+	ResultKeys []string
+}
+
+// IsPaginated returns true if the Paginator describes an operation that
+// returns tokens for fetching further pages. Some paginator definitions only
+// name the result key of an operation that is not actually paginated.
+func (p *Paginator) IsPaginated() bool {
+	return len(p.InputTokens) > 0 || len(p.OutputTokens) > 0
+}
+
+func newPaginator(opName string, spec *paginatorSpec) *Paginator {
+	return &Paginator{
+		Operation:    opName,
+		InputTokens:  spec.InputToken,
+		OutputTokens: spec.OutputToken,
+		LimitKey:     spec.LimitKey,
+		MoreResults:  spec.MoreResults,
+		ResultKeys:   spec.ResultKey,
+	}
+}
+
+// GetPaginators returns the API's paginator definitions sorted by operation
+// name
+func (a *API) GetPaginators() []*Paginator {
+	res := make([]*Paginator, 0, len(a.apiSpec.Paginators))
+	for opName, spec := range a.apiSpec.Paginators {
+		res = append(res, newPaginator(opName, spec))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Operation < res[j].Operation
+	})
+	return res
+}
+
+// GetPaginator returns the paginator definition for the supplied operation
+// name or nil if the operation has no paginator
+func (a *API) GetPaginator(opName string) *Paginator {
+	spec, found := a.apiSpec.Paginators[opName]
+	if !found {
+		return nil
+	}
+	return newPaginator(opName, spec)
+}
+
+// paginationExtension returns the value of the x-aws-pagination extension
+// for an operation's paginator
+func paginationExtension(spec *paginatorSpec) map[string]interface{} {
+	ext := map[string]interface{}{}
+	if len(spec.InputToken) > 0 {
+		ext["inputToken"] = []string(spec.InputToken)
+	}
+	if len(spec.OutputToken) > 0 {
+		ext["outputToken"] = []string(spec.OutputToken)
+	}
+	if spec.LimitKey != "" {
+		ext["limitKey"] = spec.LimitKey
+	}
+	if spec.MoreResults != "" {
+		ext["moreResults"] = spec.MoreResults
+	}
+	if len(spec.ResultKey) > 0 {
+		ext["resultKey"] = []string(spec.ResultKey)
+	}
+	return ext
+}
@@ -0,0 +1,176 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const dynamodbTablesModel = `{
+  "metadata": {
+    "apiVersion": "2012-08-10",
+    "endpointPrefix": "dynamodb",
+    "jsonVersion": "1.0",
+    "protocol": "json",
+    "serviceFullName": "Amazon DynamoDB",
+    "serviceId": "DynamoDB",
+    "signatureVersion": "v4",
+    "targetPrefix": "DynamoDB_20120810"
+  },
+  "operations": {
+    "DescribeTable": {
+      "name": "DescribeTable",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeTableInput"},
+      "output": {"shape": "DescribeTableOutput"},
+      "errors": [{"shape": "ResourceNotFoundException"}]
+    },
+    "ListTables": {
+      "name": "ListTables",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "ListTablesInput"},
+      "output": {"shape": "ListTablesOutput"}
+    }
+  },
+  "shapes": {
+    "DescribeTableInput": {
+      "type": "structure",
+      "required": ["TableName"],
+      "members": {"TableName": {"shape": "String"}}
+    },
+    "DescribeTableOutput": {
+      "type": "structure",
+      "members": {"Table": {"shape": "TableDescription"}}
+    },
+    "TableDescription": {
+      "type": "structure",
+      "members": {
+        "TableName": {"shape": "String"},
+        "TableStatus": {"shape": "String"}
+      }
+    },
+    "ListTablesInput": {
+      "type": "structure",
+      "members": {
+        "ExclusiveStartTableName": {"shape": "String"},
+        "Limit": {"shape": "Integer"}
+      }
+    },
+    "ListTablesOutput": {
+      "type": "structure",
+      "members": {
+        "TableNames": {"shape": "TableNameList"},
+        "LastEvaluatedTableName": {"shape": "String"}
+      }
+    },
+    "TableNameList": {"type": "list", "member": {"shape": "String"}},
+    "ResourceNotFoundException": {
+      "type": "structure",
+      "members": {"message": {"shape": "String"}},
+      "exception": true
+    },
+    "Integer": {"type": "integer"},
+    "String": {"type": "string"}
+  }
+}`
+
+const dynamodbPaginators = `{
+  "pagination": {
+    "DescribeTable": {
+      "result_key": "Table"
+    },
+    "ListTables": {
+      "input_token": "ExclusiveStartTableName",
+      "output_token": "LastEvaluatedTableName",
+      "limit_key": "Limit",
+      "result_key": ["TableNames"]
+    }
+  }
+}`
+
+func TestParsePaginators(t *testing.T) {
+	paginators, err := parsePaginators([]byte(dynamodbPaginators))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Single strings and lists of strings are both lists
+	want := &paginatorSpec{
+		InputToken:  stringOrList{"ExclusiveStartTableName"},
+		OutputToken: stringOrList{"LastEvaluatedTableName"},
+		LimitKey:    "Limit",
+		ResultKey:   stringOrList{"TableNames"},
+	}
+	if got := paginators["ListTables"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected ListTables paginator %+v, got %+v", want, got)
+	}
+	if _, err := parsePaginators([]byte(`{"pagination": {"ListTables": {"result_key": 1}}}`)); err == nil {
+		t.Errorf("expected an error for a numeric result key")
+	}
+}
+
+func TestGetPaginators(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:      dynamodbTablesModel,
+		model.PaginatorsFile: dynamodbPaginators,
+	})
+	got := []string{}
+	for _, p := range api.GetPaginators() {
+		got = append(got, p.Operation)
+	}
+	if strings.Join(got, ",") != "DescribeTable,ListTables" {
+		t.Errorf("expected paginators for DescribeTable and ListTables, got %v", got)
+	}
+	// DescribeTable only has a result key, it does not return pages
+	if api.GetPaginator("DescribeTable").IsPaginated() {
+		t.Errorf("expected DescribeTable not to be paginated")
+	}
+	if !api.GetPaginator("ListTables").IsPaginated() {
+		t.Errorf("expected ListTables to be paginated")
+	}
+	if p := api.GetPaginator("CreateTable"); p != nil {
+		t.Errorf("expected no paginator for CreateTable, got %+v", p)
+	}
+}
+
+func TestPaginationExtension(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:      dynamodbTablesModel,
+		model.PaginatorsFile: dynamodbPaginators,
+	})
+	swagger := testSchema(t, api)
+	tests := []struct {
+		operationID string
+		want        map[string]interface{}
+	}{
+		{"ListTables", map[string]interface{}{
+			"inputToken":  []string{"ExclusiveStartTableName"},
+			"outputToken": []string{"LastEvaluatedTableName"},
+			"limitKey":    "Limit",
+			"resultKey":   []string{"TableNames"},
+		}},
+		{"DescribeTable", map[string]interface{}{
+			"resultKey": []string{"Table"},
+		}},
+	}
+	for _, test := range tests {
+		_, op := findOperation(t, swagger, test.operationID)
+		if got := op.Extensions["x-aws-pagination"]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected x-aws-pagination %v, got %v", test.operationID, test.want, got)
+		}
+	}
+
+	// Without paginators-1.json, no operation is paginated
+	swagger = testSchema(t, newTestAPI(t, "dynamodb", dynamodbTablesModel))
+	_, op := findOperation(t, swagger, "ListTables")
+	if ext, found := op.Extensions["x-aws-pagination"]; found {
+		t.Errorf("expected no x-aws-pagination, got %v", ext)
+	}
+}
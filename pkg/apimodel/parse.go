@@ -9,20 +9,57 @@ package apimodel
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
 )
 
 type metadataSpec struct {
-	APIVersion string `json:"apiVersion"`
-	FullName   string `json:"serviceFullName"`
-	Alias      string `json:"serviceId"`
-	Protocol   string `json:"protocol"`
+	APIVersion   string `json:"apiVersion"`
+	FullName     string `json:"serviceFullName"`
+	Abbreviation string `json:"serviceAbbreviation"`
+	Alias        string `json:"serviceId"`
+	Protocol     string `json:"protocol"`
+	// EndpointPrefix is the service's host name prefix in endpoints
+	EndpointPrefix string `json:"endpointPrefix"`
+	// JSONVersion is the version of the application/x-amz-json media type
+	// used by json protocol APIs
+	JSONVersion string `json:"jsonVersion"`
+	// XMLNamespace is the namespace of the XML responses of query protocol
+	// APIs
+	XMLNamespace string `json:"xmlNamespace"`
+	// SignatureVersion is how requests are signed, e.g. "v4" or "s3v4", or
+	// "bearer" for APIs authenticated with a bearer token
+	SignatureVersion string `json:"signatureVersion"`
+	// SigningName is the service name in the credential scope of signed
+	// requests, if different from the endpoint prefix
+	SigningName string `json:"signingName"`
+	// TargetPrefix prefixes the operation name in the X-Amz-Target header of
+	// json protocol requests, e.g. "DynamoDB_20120810"
+	TargetPrefix string `json:"targetPrefix"`
+}
+
+type xmlNamespaceSpec struct {
+	Prefix string `json:"prefix"`
+	URI    string `json:"uri"`
 }
 
 type shapeRefSpec struct {
-	ShapeName *string `json:"shape,omitempty"`
-	Location  *string `json:"location,omitempty"`
+	ShapeName         *string           `json:"shape,omitempty"`
+	Location          *string           `json:"location,omitempty"`
+	LocationName      string            `json:"locationName"`
+	QueryName         string            `json:"queryName"`
+	XMLNamespace      *xmlNamespaceSpec `json:"xmlNamespace,omitempty"`
+	XMLAttribute      bool              `json:"xmlAttribute"`
+	Flattened         bool              `json:"flattened"`
+	Deprecated        bool              `json:"deprecated"`
+	DeprecatedMessage string            `json:"deprecatedMessage"`
+	IdempotencyToken  bool              `json:"idempotencyToken"`
+	Streaming         bool              `json:"streaming"`
+	JSONValue         bool              `json:"jsonvalue"`
+	HostLabel         bool              `json:"hostLabel"`
+	Box               bool              `json:"box"`
+	TimestampFormat   string            `json:"timestampFormat"`
+	// ResultWrapper is the name of the element wrapping the output members
+	// of query protocol responses
+	ResultWrapper string `json:"resultWrapper"`
 }
 
 type httpSpec struct {
@@ -31,11 +68,21 @@ type httpSpec struct {
 	ResponseCode *int    `json:"responseCode"`
 }
 
+type endpointSpec struct {
+	HostPrefix string `json:"hostPrefix"`
+}
+
 type opSpec struct {
-	HTTP   *httpSpec       `json:"http,omitempty"`
-	Input  *shapeRefSpec   `json:"input,omitempty"`
-	Output *shapeRefSpec   `json:"output,omitempty"`
-	Errors []*shapeRefSpec `json:"errors"`
+	HTTP                 *httpSpec       `json:"http,omitempty"`
+	Input                *shapeRefSpec   `json:"input,omitempty"`
+	Output               *shapeRefSpec   `json:"output,omitempty"`
+	Errors               []*shapeRefSpec `json:"errors"`
+	AuthType             string          `json:"authtype"`
+	Endpoint             *endpointSpec   `json:"endpoint,omitempty"`
+	HTTPChecksumRequired bool            `json:"httpChecksumRequired"`
+	Deprecated           bool            `json:"deprecated"`
+	DeprecatedMessage    string          `json:"deprecatedMessage"`
+	DocumentationURL     string          `json:"documentationUrl"`
 }
 
 type errShapeSpec struct {
@@ -45,22 +92,44 @@ type errShapeSpec struct {
 }
 
 type shapeSpec struct {
-	Type       string                   `json:"type"`
-	Exception  bool                     `json:"exception"`
-	Error      *errShapeSpec            `json:"error,omitempty"`
-	Required   []string                 `json:"required"`
-	Members    map[string]*shapeRefSpec `json:"members"`
-	ListMember *shapeRefSpec            `json:"member,omitempty"` // for list types
-	Min        *float64                 `json:"min,omitempty"`
-	Max        *float64                 `json:"max,omitempty"`
-	Pattern    *string                  `json:"pattern,omitempty"`
-	Enum       []interface{}            `json:"enum"`
+	Type              string                   `json:"type"`
+	Exception         bool                     `json:"exception"`
+	Error             *errShapeSpec            `json:"error,omitempty"`
+	Required          []string                 `json:"required"`
+	Members           map[string]*shapeRefSpec `json:"members"`
+	ListMember        *shapeRefSpec            `json:"member,omitempty"` // for list types
+	MapKey            *shapeRefSpec            `json:"key,omitempty"`    // for map types
+	MapValue          *shapeRefSpec            `json:"value,omitempty"`  // for map types
+	Min               *float64                 `json:"min,omitempty"`
+	Max               *float64                 `json:"max,omitempty"`
+	Pattern           *string                  `json:"pattern,omitempty"`
+	Enum              []interface{}            `json:"enum"`
+	Payload           string                   `json:"payload"`
+	LocationName      string                   `json:"locationName"`
+	XMLNamespace      *xmlNamespaceSpec        `json:"xmlNamespace,omitempty"`
+	Flattened         bool                     `json:"flattened"`
+	Deprecated        bool                     `json:"deprecated"`
+	DeprecatedMessage string                   `json:"deprecatedMessage"`
+	Sensitive         bool                     `json:"sensitive"`
+	Streaming         bool                     `json:"streaming"`
+	EventStream       bool                     `json:"eventstream"`
+	Event             bool                     `json:"event"`
+	Document          bool                     `json:"document"`
+	Union             bool                     `json:"union"`
+	Box               bool                     `json:"box"`
+	TimestampFormat   string                   `json:"timestampFormat"`
 }
 
 type apiSpec struct {
 	Metadata   metadataSpec          `json:"metadata"`
 	Operations map[string]*opSpec    `json:"operations"`
 	Shapes     map[string]*shapeSpec `json:"shapes"`
+	// Paginators is parsed from paginators-1.json, not api-2.json
+	Paginators map[string]*paginatorSpec `json:"-"`
+	// Waiters is parsed from waiters-2.json, not api-2.json
+	Waiters map[string]*waiterSpec `json:"-"`
+	// Examples is parsed from examples-1.json, not api-2.json
+	Examples map[string][]*exampleSpec `json:"-"`
 }
 
 type shapeDocSpec struct {
@@ -74,22 +143,23 @@ type docSpec struct {
 	Shapes     map[string]*shapeDocSpec `json:"shapes"`
 }
 
-func parseFrom(modelPath string, docPath string) (*apiSpec, *docSpec, error) {
-	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
-		return nil, nil, fmt.Errorf("expected to find %s", modelPath)
-	}
-	if _, err := os.Stat(docPath); os.IsNotExist(err) {
-		return nil, nil, fmt.Errorf("expected to find %s", docPath)
+// parseFrom returns the apiSpec and docSpec described by the supplied model
+// and doc JSON. The model may be either a CORAL api-2.json document or a
+// Smithy JSON AST document, which carries its documentation inline. docBytes
+// may be nil if there is no doc JSON for the model.
+func parseFrom(modelBytes []byte, docBytes []byte) (*apiSpec, *docSpec, error) {
+	if isSmithyModel(modelBytes) {
+		return parseSmithy(modelBytes)
 	}
 	var apiSpec apiSpec
-	b, err := ioutil.ReadFile(modelPath)
-	if err = json.Unmarshal(b, &apiSpec); err != nil {
-		return nil, nil, err
+	if err := json.Unmarshal(modelBytes, &apiSpec); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode model: %v", err)
 	}
 	var docSpec docSpec
-	b, err = ioutil.ReadFile(docPath)
-	if err = json.Unmarshal(b, &docSpec); err != nil {
-		return nil, nil, err
+	if docBytes != nil {
+		if err := json.Unmarshal(docBytes, &docSpec); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode docs: %v", err)
+		}
 	}
 	return &apiSpec, &docSpec, nil
 }
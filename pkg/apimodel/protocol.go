@@ -0,0 +1,342 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"strings"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// The protocols AWS APIs use to serialize requests and responses
+const (
+	ProtocolJSON     = "json"
+	ProtocolRESTJSON = "rest-json"
+	ProtocolRESTXML  = "rest-xml"
+	ProtocolQuery    = "query"
+	ProtocolEC2      = "ec2"
+)
+
+const (
+	mediaTypeJSON        = "application/json"
+	mediaTypeXML         = "application/xml"
+	mediaTypeTextXML     = "text/xml"
+	mediaTypeForm        = "application/x-www-form-urlencoded"
+	mediaTypeOctetStream = "application/octet-stream"
+	// mediaTypeAmzJSON is suffixed with the API's JSON version, e.g.
+	// "application/x-amz-json-1.1"
+	mediaTypeAmzJSON = "application/x-amz-json-"
+)
+
+// defaultJSONVersion is the JSON version of json protocol APIs whose metadata
+// does not specify one
+const defaultJSONVersion = "1.0"
+
+// isQuery returns true if the API sends requests as form-encoded Action and
+// Version parameters and receives XML responses
+func (m *metadataSpec) isQuery() bool {
+	return m.Protocol == ProtocolQuery || m.Protocol == ProtocolEC2
+}
+
+// isXML returns true if the API's responses are XML documents
+func (m *metadataSpec) isXML() bool {
+	return m.Protocol == ProtocolRESTXML || m.isQuery()
+}
+
+// requestMediaType returns the media type of request bodies sent to the API
+func (m *metadataSpec) requestMediaType() string {
+	switch m.Protocol {
+	case ProtocolJSON:
+		if m.JSONVersion == "" {
+			return mediaTypeAmzJSON + defaultJSONVersion
+		}
+		return mediaTypeAmzJSON + m.JSONVersion
+	case ProtocolRESTXML:
+		return mediaTypeXML
+	case ProtocolQuery, ProtocolEC2:
+		return mediaTypeForm
+	}
+	return mediaTypeJSON
+}
+
+// responseMediaType returns the media type of response bodies returned by
+// the API
+func (m *metadataSpec) responseMediaType() string {
+	if m.isQuery() {
+		return mediaTypeTextXML
+	}
+	return m.requestMediaType()
+}
+
+// xmlObject is an OpenAPI XML object, which describes how a schema is
+// serialized as an XML element or attribute
+type xmlObject struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	Attribute bool   `json:"attribute,omitempty"`
+	Wrapped   bool   `json:"wrapped,omitempty"`
+}
+
+// withNamespace sets the XML object's namespace, if any, and returns the XML
+// object
+func (x *xmlObject) withNamespace(ns *xmlNamespaceSpec) *xmlObject {
+	if ns != nil {
+		x.Namespace = ns.URI
+		x.Prefix = ns.Prefix
+	}
+	return x
+}
+
+// merge sets the non-empty fields of other on the XML object
+func (x *xmlObject) merge(other *xmlObject) {
+	if other.Name != "" {
+		x.Name = other.Name
+	}
+	if other.Namespace != "" {
+		x.Namespace = other.Namespace
+		x.Prefix = other.Prefix
+	}
+	x.Attribute = x.Attribute || other.Attribute
+	x.Wrapped = x.Wrapped || other.Wrapped
+}
+
+// isEmpty returns true if the XML object does not change how a schema is
+// serialized
+func (x *xmlObject) isEmpty() bool {
+	return *x == xmlObject{}
+}
+
+// rootXML returns the XML object describing the root element of an XML
+// request or response body for an operation's input or output shape, or nil
+// if the element is named after the shape. The operation's reference to the
+// shape takes precedence over the shape itself, and the API's namespace is
+// used if neither has a namespace.
+func (m *metadataSpec) rootXML(ref *shapeRefSpec, ss *shapeSpec) *xmlObject {
+	x := &xmlObject{Name: ss.LocationName}
+	if ref.LocationName != "" {
+		x.Name = ref.LocationName
+	}
+	switch {
+	case ref.XMLNamespace != nil:
+		x.withNamespace(ref.XMLNamespace)
+	case ss.XMLNamespace != nil:
+		x.withNamespace(ss.XMLNamespace)
+	default:
+		x.Namespace = m.XMLNamespace
+	}
+	if x.isEmpty() {
+		return nil
+	}
+	return x
+}
+
+// withXML returns a schema reference that serializes the referenced schema as
+// described by the supplied XML object. OpenAPI 3.0 ignores the siblings of
+// a $ref, so a referenced component schema is wrapped in an allOf.
+func withXML(schemaRef *oai.SchemaRef, x *xmlObject) *oai.SchemaRef {
+	if x == nil || x.isEmpty() {
+		return schemaRef
+	}
+	if schemaRef.Ref == "" {
+		// Merge into the XML object an inline schema may already have, e.g.
+		// the wrapping of a list
+		if existing, ok := schemaRef.Value.XML.(*xmlObject); ok {
+			existing.merge(x)
+		} else {
+			schemaRef.Value.XML = x
+		}
+		return schemaRef
+	}
+	return oai.NewSchemaRef("", &oai.Schema{
+		AllOf: []*oai.SchemaRef{schemaRef},
+		XML:   x,
+	})
+}
+
+// withExtension returns a schema reference with the supplied OpenAPI
+// extension. A referenced component schema is wrapped in an allOf.
+func withExtension(schemaRef *oai.SchemaRef, key string, value interface{}) *oai.SchemaRef {
+	if schemaRef.Ref != "" {
+		schemaRef = oai.NewSchemaRef("", &oai.Schema{
+			AllOf: []*oai.SchemaRef{schemaRef},
+		})
+	}
+	addExtension(&schemaRef.Value.ExtensionProps, key, value)
+	return schemaRef
+}
+
+// bodyFormat describes how an operation's request or response body is
+// serialized
+type bodyFormat struct {
+	mediaType string
+	// root is the XML object describing the root element of an XML body, or
+	// nil
+	root *xmlObject
+}
+
+// newContent returns OpenAPI content with a single media type of the body
+// format, described by the supplied schema
+func (f *bodyFormat) newContent(schemaRef *oai.SchemaRef) oai.Content {
+	content := oai.NewContent()
+	content[f.mediaType] = oai.NewMediaType().WithSchemaRef(withXML(schemaRef, f.root))
+	return content
+}
+
+// queryActionSchema returns the schema of the Action and Version form fields
+// that select the operation and API version of a query protocol request
+func queryActionSchema(opName string, apiVersion string) *oai.Schema {
+	schema := oai.NewObjectSchema()
+	schema.WithProperty("Action", oai.NewStringSchema().WithEnum(opName))
+	schema.WithProperty("Version", oai.NewStringSchema().WithEnum(apiVersion))
+	schema.Required = []string{"Action", "Version"}
+	return schema
+}
+
+// queryRequestBody returns the form-encoded request body of a query protocol
+// operation, which contains the Action and Version fields alongside the
+// members of the operation's input shape, if it has one
+func queryRequestBody(
+	opName string,
+	input *shapeRefSpec,
+	meta *metadataSpec,
+) *oai.RequestBody {
+	schemaRef := oai.NewSchemaRef("", queryActionSchema(opName, meta.APIVersion))
+	if input != nil && input.ShapeName != nil {
+		schemaRef = oai.NewSchemaRef("", &oai.Schema{
+			AllOf: []*oai.SchemaRef{schemaRef, componentSchemaRef(*input.ShapeName)},
+		})
+	}
+	content := oai.NewContent()
+	content[mediaTypeForm] = oai.NewMediaType().WithSchemaRef(schemaRef)
+	return oai.NewRequestBody().WithRequired(true).WithContent(content)
+}
+
+// queryResultWrapper returns the name of the element wrapping the output
+// members of a query protocol response
+func queryResultWrapper(opName string, output *shapeRefSpec) string {
+	if output.ResultWrapper != "" {
+		return output.ResultWrapper
+	}
+	return opName + "Result"
+}
+
+// queryResponseContent returns the text/xml content of a query protocol
+// operation's response, which is an <OpNameResponse> envelope. The query
+// protocol wraps the output members, if any, in a result wrapper element and
+// puts the request ID in a ResponseMetadata element, while the EC2 protocol
+// puts the output members and a requestId element directly in the envelope.
+func queryResponseContent(
+	opName string,
+	output *shapeRefSpec,
+	meta *metadataSpec,
+) oai.Content {
+	envelope := oai.NewObjectSchema()
+	if meta.Protocol == ProtocolEC2 {
+		envelope.WithProperty("requestId", oai.NewStringSchema())
+		envelope.Required = []string{"requestId"}
+		if output != nil {
+			envelope = &oai.Schema{
+				AllOf: []*oai.SchemaRef{
+					componentSchemaRef(*output.ShapeName),
+					oai.NewSchemaRef("", envelope),
+				},
+			}
+		}
+	} else {
+		respMeta := oai.NewObjectSchema()
+		respMeta.WithProperty("RequestId", oai.NewStringSchema())
+		respMeta.Required = []string{"RequestId"}
+		envelope.WithProperty("ResponseMetadata", respMeta)
+		envelope.Required = []string{"ResponseMetadata"}
+		if output != nil {
+			envelope.WithPropertyRef(
+				queryResultWrapper(opName, output),
+				componentSchemaRef(*output.ShapeName),
+			)
+		}
+	}
+	envelope.XML = &xmlObject{Name: opName + "Response", Namespace: meta.XMLNamespace}
+	content := oai.NewContent()
+	content[mediaTypeTextXML] = oai.NewMediaType().WithSchema(envelope)
+	return content
+}
+
+// wrapQueryExamples wraps the output of each example of a query protocol
+// response in the response's result wrapper element, matching the response
+// envelope
+func wrapQueryExamples(
+	mediaType *oai.MediaType,
+	opName string,
+	output *shapeRefSpec,
+	meta *metadataSpec,
+) {
+	if mediaType == nil || meta.Protocol == ProtocolEC2 {
+		return
+	}
+	wrapper := queryResultWrapper(opName, output)
+	for _, example := range mediaType.Examples {
+		example.Value.Value = map[string]interface{}{wrapper: example.Value.Value}
+	}
+}
+
+// querySerialization returns the x-aws-query-serialization extension of a
+// list or map shape in query protocol APIs, which describes the names of the
+// form fields the shape's elements are serialized as, relative to the name of
+// the member containing the shape, or nil for other shapes. Lists become
+// "{name}.member.{n}" fields, or "{name}.{n}" fields when flattened or for EC2,
+// and maps become "{name}.entry.{n}.key" and "{name}.entry.{n}.value" fields.
+// Indexes start at 1.
+func (m *metadataSpec) querySerialization(ss *shapeSpec) interface{} {
+	if !m.isQuery() {
+		return nil
+	}
+	switch ss.Type {
+	case "list":
+		if ss.Flattened || m.Protocol == ProtocolEC2 {
+			return "{name}.{n}"
+		}
+		element := "member"
+		if ss.ListMember != nil && ss.ListMember.LocationName != "" {
+			element = ss.ListMember.LocationName
+		}
+		return "{name}." + element + ".{n}"
+	case "map":
+		entry := "{name}.entry.{n}"
+		if ss.Flattened {
+			entry = "{name}.{n}"
+		}
+		key, value := "key", "value"
+		if ss.MapKey != nil && ss.MapKey.LocationName != "" {
+			key = ss.MapKey.LocationName
+		}
+		if ss.MapValue != nil && ss.MapValue.LocationName != "" {
+			value = ss.MapValue.LocationName
+		}
+		return map[string]string{
+			"key":   entry + "." + key,
+			"value": entry + "." + value,
+		}
+	}
+	return nil
+}
+
+// queryName returns the name of the form field a structure member is
+// serialized as in query protocol requests. EC2 prefers the member's
+// queryName and otherwise capitalizes its locationName.
+func (m *metadataSpec) queryName(memberName string, ref *shapeRefSpec) string {
+	if m.Protocol == ProtocolEC2 {
+		if ref.QueryName != "" {
+			return ref.QueryName
+		}
+		if ref.LocationName != "" {
+			return strings.ToUpper(ref.LocationName[:1]) + ref.LocationName[1:]
+		}
+		return memberName
+	}
+	return wireName(memberName, ref)
+}
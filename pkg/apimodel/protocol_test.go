@@ -0,0 +1,382 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+const s3TaggingModel = `{
+  "metadata": {
+    "apiVersion": "2006-03-01",
+    "endpointPrefix": "s3",
+    "protocol": "rest-xml",
+    "serviceFullName": "Amazon Simple Storage Service",
+    "serviceId": "S3",
+    "signatureVersion": "s3",
+    "xmlNamespace": "http://s3.amazonaws.com/doc/2006-03-01/"
+  },
+  "operations": {
+    "PutBucketTagging": {
+      "name": "PutBucketTagging",
+      "http": {"method": "PUT", "requestUri": "/{Bucket}?tagging"},
+      "input": {"shape": "PutBucketTaggingRequest"}
+    },
+    "ListParts": {
+      "name": "ListParts",
+      "http": {"method": "GET", "requestUri": "/{Bucket}?parts"},
+      "input": {"shape": "ListPartsRequest"},
+      "output": {"shape": "ListPartsOutput"}
+    }
+  },
+  "shapes": {
+    "PutBucketTaggingRequest": {
+      "type": "structure",
+      "required": ["Bucket", "Tagging"],
+      "members": {
+        "Bucket": {"shape": "String", "location": "uri", "locationName": "Bucket"},
+        "Tagging": {
+          "shape": "Tagging",
+          "locationName": "Tagging",
+          "xmlNamespace": {"uri": "http://s3.amazonaws.com/doc/2006-03-01/"}
+        }
+      },
+      "payload": "Tagging"
+    },
+    "Tagging": {
+      "type": "structure",
+      "required": ["TagSet"],
+      "members": {"TagSet": {"shape": "TagSet"}}
+    },
+    "TagSet": {
+      "type": "list",
+      "member": {"shape": "Tag", "locationName": "Tag"}
+    },
+    "Tag": {
+      "type": "structure",
+      "members": {
+        "Key": {"shape": "String"},
+        "Value": {"shape": "String"}
+      }
+    },
+    "ListPartsRequest": {
+      "type": "structure",
+      "required": ["Bucket"],
+      "members": {
+        "Bucket": {"shape": "String", "location": "uri", "locationName": "Bucket"}
+      }
+    },
+    "ListPartsOutput": {
+      "type": "structure",
+      "members": {
+        "Parts": {"shape": "Parts", "locationName": "Part"},
+        "Grantee": {"shape": "Grantee"}
+      }
+    },
+    "Parts": {
+      "type": "list",
+      "member": {"shape": "Part"},
+      "flattened": true
+    },
+    "Part": {
+      "type": "structure",
+      "members": {"PartNumber": {"shape": "Integer"}}
+    },
+    "Grantee": {
+      "type": "structure",
+      "members": {
+        "Type": {"shape": "String", "locationName": "xsi:type", "xmlAttribute": true}
+      },
+      "xmlNamespace": {"prefix": "xsi", "uri": "http://www.w3.org/2001/XMLSchema-instance"}
+    },
+    "Integer": {"type": "integer"},
+    "String": {"type": "string"}
+  }
+}`
+
+func TestMediaTypes(t *testing.T) {
+	tests := []struct {
+		meta     metadataSpec
+		request  string
+		response string
+	}{
+		{metadataSpec{Protocol: ProtocolJSON, JSONVersion: "1.1"}, "application/x-amz-json-1.1", "application/x-amz-json-1.1"},
+		{metadataSpec{Protocol: ProtocolJSON}, "application/x-amz-json-1.0", "application/x-amz-json-1.0"},
+		{metadataSpec{Protocol: ProtocolRESTJSON}, mediaTypeJSON, mediaTypeJSON},
+		{metadataSpec{Protocol: ProtocolRESTXML}, mediaTypeXML, mediaTypeXML},
+		{metadataSpec{Protocol: ProtocolQuery}, mediaTypeForm, mediaTypeTextXML},
+		{metadataSpec{Protocol: ProtocolEC2}, mediaTypeForm, mediaTypeTextXML},
+	}
+	for _, test := range tests {
+		name := test.meta.Protocol + " " + test.meta.JSONVersion
+		if got := test.meta.requestMediaType(); got != test.request {
+			t.Errorf("%s: expected request media type %s, got %s", name, test.request, got)
+		}
+		if got := test.meta.responseMediaType(); got != test.response {
+			t.Errorf("%s: expected response media type %s, got %s", name, test.response, got)
+		}
+	}
+
+	// The media types are used for the operations' bodies
+	swagger := testSchema(t, newTestAPI(t, "dynamodb", dynamodbTablesModel))
+	_, op := findOperation(t, swagger, "ListTables")
+	if op.RequestBody.Value.Content.Get("application/x-amz-json-1.0") == nil {
+		t.Errorf("expected a request body of the JSON version, got %v", op.RequestBody.Value.Content)
+	}
+	if op.Responses["200"].Value.Content.Get("application/x-amz-json-1.0") == nil {
+		t.Errorf("expected a response body of the JSON version, got %v", op.Responses["200"].Value.Content)
+	}
+}
+
+// xmlOf returns the XML object of a schema, failing the test if the schema
+// has none
+func xmlOf(t *testing.T, name string, schema *oai.Schema) *xmlObject {
+	t.Helper()
+	x, ok := schema.XML.(*xmlObject)
+	if !ok || x == nil {
+		t.Fatalf("%s: expected an XML object, got %v", name, schema.XML)
+	}
+	return x
+}
+
+func TestXMLObjects(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "s3", s3TaggingModel))
+	schemas := swagger.Components.Schemas
+	s3NS := "http://s3.amazonaws.com/doc/2006-03-01/"
+	xsiNS := "http://www.w3.org/2001/XMLSchema-instance"
+
+	// Lists are wrapped, and their items are named "member" unless the list
+	// member is named
+	tagSet := schemas["TagSet"].Value
+	if got := *xmlOf(t, "TagSet", tagSet); got != (xmlObject{Wrapped: true}) {
+		t.Errorf("expected a wrapped TagSet, got %+v", got)
+	}
+	if got := *xmlOf(t, "TagSet items", tagSet.Items.Value); got != (xmlObject{Name: "Tag"}) {
+		t.Errorf("expected TagSet items named Tag, got %+v", got)
+	}
+	// Flattened lists are not wrapped and are named after the member
+	// containing them
+	parts := schemas["Parts"].Value
+	if parts.XML != nil {
+		t.Errorf("expected Parts not to be wrapped, got %+v", parts.XML)
+	}
+	output := schemas["ListPartsOutput"].Value
+	if got := *xmlOf(t, "ListPartsOutput.Parts", output.Properties["Parts"].Value); got != (xmlObject{Name: "Part"}) {
+		t.Errorf("expected ListPartsOutput.Parts named Part, got %+v", got)
+	}
+	// Namespaces apply to shapes and attributes to members
+	if got := *xmlOf(t, "Grantee", schemas["Grantee"].Value); got != (xmlObject{Namespace: xsiNS, Prefix: "xsi"}) {
+		t.Errorf("expected Grantee in the xsi namespace, got %+v", got)
+	}
+	granteeType := schemas["Grantee"].Value.Properties["Type"].Value
+	if got := *xmlOf(t, "Grantee.Type", granteeType); got != (xmlObject{Name: "xsi:type", Attribute: true}) {
+		t.Errorf("expected Grantee.Type to be the xsi:type attribute, got %+v", got)
+	}
+
+	// The root element of a body is described by an allOf wrapping the
+	// shape's component schema. The operation's reference to the shape
+	// names the element, and the API's namespace is used by default.
+	_, putOp := findOperation(t, swagger, "PutBucketTagging")
+	_, listOp := findOperation(t, swagger, "ListParts")
+	tests := []struct {
+		operationID string
+		content     oai.Content
+		ref         string
+		want        xmlObject
+	}{
+		{"PutBucketTagging", putOp.RequestBody.Value.Content, "#/components/schemas/Tagging", xmlObject{Name: "Tagging", Namespace: s3NS}},
+		{"ListParts", listOp.Responses["200"].Value.Content, "#/components/schemas/ListPartsOutput", xmlObject{Namespace: s3NS}},
+	}
+	for _, test := range tests {
+		mt := test.content.Get(mediaTypeXML)
+		if mt == nil {
+			t.Errorf("%s: expected an XML body, got %v", test.operationID, test.content)
+			continue
+		}
+		allOf := mt.Schema.Value.AllOf
+		if len(allOf) != 1 || allOf[0].Ref != test.ref {
+			t.Errorf("%s: expected an allOf of %s, got %v", test.operationID, test.ref, mt.Schema)
+			continue
+		}
+		if got := *xmlOf(t, test.operationID, mt.Schema.Value); got != test.want {
+			t.Errorf("%s: expected the root element %+v, got %+v", test.operationID, test.want, got)
+		}
+	}
+}
+
+const sqsModel = `{
+  "metadata": {
+    "apiVersion": "2012-11-05",
+    "endpointPrefix": "sqs",
+    "protocol": "query",
+    "serviceFullName": "Amazon Simple Queue Service",
+    "serviceId": "SQS",
+    "signatureVersion": "v4",
+    "xmlNamespace": "http://queue.amazonaws.com/doc/2012-11-05/"
+  },
+  "operations": {
+    "DeleteQueue": {
+      "name": "DeleteQueue",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DeleteQueueRequest"}
+    },
+    "GetQueueAttributes": {
+      "name": "GetQueueAttributes",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "GetQueueAttributesRequest"},
+      "output": {"shape": "GetQueueAttributesResult", "resultWrapper": "GetQueueAttributesResult"}
+    },
+    "ListQueues": {
+      "name": "ListQueues",
+      "http": {"method": "POST", "requestUri": "/"},
+      "output": {"shape": "ListQueuesResult"}
+    }
+  },
+  "shapes": {
+    "DeleteQueueRequest": {
+      "type": "structure",
+      "required": ["QueueUrl"],
+      "members": {"QueueUrl": {"shape": "String"}}
+    },
+    "GetQueueAttributesRequest": {
+      "type": "structure",
+      "required": ["QueueUrl"],
+      "members": {
+        "QueueUrl": {"shape": "String"},
+        "AttributeNames": {"shape": "AttributeNameList"}
+      }
+    },
+    "GetQueueAttributesResult": {
+      "type": "structure",
+      "members": {
+        "Attributes": {"shape": "QueueAttributeMap", "locationName": "Attribute"}
+      }
+    },
+    "ListQueuesResult": {
+      "type": "structure",
+      "members": {"QueueUrls": {"shape": "QueueUrlList"}}
+    },
+    "AttributeNameList": {
+      "type": "list",
+      "member": {"shape": "String", "locationName": "AttributeName"},
+      "flattened": true
+    },
+    "QueueUrlList": {
+      "type": "list",
+      "member": {"shape": "String", "locationName": "QueueUrl"}
+    },
+    "QueueAttributeMap": {
+      "type": "map",
+      "key": {"shape": "String", "locationName": "Name"},
+      "value": {"shape": "String", "locationName": "Value"},
+      "flattened": true
+    },
+    "MessageAttributeMap": {
+      "type": "map",
+      "key": {"shape": "String"},
+      "value": {"shape": "String"}
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+func TestQueryResponseContent(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "sqs", sqsModel))
+	ns := "http://queue.amazonaws.com/doc/2012-11-05/"
+	tests := []struct {
+		operationID string
+		// wrapper is the name of the element wrapping the output members,
+		// or empty if the operation has no output
+		wrapper string
+		ref     string
+	}{
+		{"GetQueueAttributes", "GetQueueAttributesResult", "#/components/schemas/GetQueueAttributesResult"},
+		// Result wrappers default to the operation name suffixed by Result
+		{"ListQueues", "ListQueuesResult", "#/components/schemas/ListQueuesResult"},
+		// Responses without output still carry the request ID
+		{"DeleteQueue", "", ""},
+	}
+	for _, test := range tests {
+		_, op := findOperation(t, swagger, test.operationID)
+		mt := op.Responses["200"].Value.Content.Get(mediaTypeTextXML)
+		if mt == nil {
+			t.Errorf("%s: expected a %s response, got %v", test.operationID, mediaTypeTextXML, op.Responses["200"].Value.Content)
+			continue
+		}
+		envelope := mt.Schema.Value
+		want := xmlObject{Name: test.operationID + "Response", Namespace: ns}
+		if got := *xmlOf(t, test.operationID, envelope); got != want {
+			t.Errorf("%s: expected the envelope element %+v, got %+v", test.operationID, want, got)
+		}
+		respMeta := envelope.Properties["ResponseMetadata"]
+		if respMeta == nil || respMeta.Value.Properties["RequestId"] == nil {
+			t.Errorf("%s: expected a ResponseMetadata element with a RequestId, got %v", test.operationID, respMeta)
+		}
+		if len(envelope.Required) != 1 || envelope.Required[0] != "ResponseMetadata" {
+			t.Errorf("%s: expected ResponseMetadata to be required, got %v", test.operationID, envelope.Required)
+		}
+		wantProps := 1
+		if test.wrapper != "" {
+			wantProps++
+			if ref := envelope.Properties[test.wrapper]; ref == nil || ref.Ref != test.ref {
+				t.Errorf("%s: expected the result wrapper %s referring to %s, got %v", test.operationID, test.wrapper, test.ref, ref)
+			}
+		}
+		if len(envelope.Properties) != wantProps {
+			t.Errorf("%s: expected %d envelope properties, got %d", test.operationID, wantProps, len(envelope.Properties))
+		}
+	}
+
+	// EC2 puts the output members and the request ID directly in the
+	// envelope
+	swagger = testSchema(t, newTestAPI(t, "ec2", ec2Model))
+	_, op := findOperation(t, swagger, "CreateVpc")
+	envelope := op.Responses["200"].Value.Content.Get(mediaTypeTextXML).Schema.Value
+	if len(envelope.AllOf) != 2 || envelope.AllOf[0].Ref != "#/components/schemas/CreateVpcResult" {
+		t.Fatalf("CreateVpc: expected an allOf of CreateVpcResult and the request ID, got %v", envelope.AllOf)
+	}
+	if envelope.AllOf[1].Value.Properties["requestId"] == nil {
+		t.Errorf("CreateVpc: expected a requestId element, got %v", envelope.AllOf[1].Value.Properties)
+	}
+	if got := xmlOf(t, "CreateVpc", envelope).Name; got != "CreateVpcResponse" {
+		t.Errorf("CreateVpc: expected the envelope element CreateVpcResponse, got %s", got)
+	}
+}
+
+func TestQuerySerialization(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "sqs", sqsModel))
+	tests := []struct {
+		shapeName string
+		want      interface{}
+	}{
+		{"QueueUrlList", "{name}.QueueUrl.{n}"},
+		{"AttributeNameList", "{name}.{n}"},
+		{"QueueAttributeMap", map[string]string{"key": "{name}.{n}.Name", "value": "{name}.{n}.Value"}},
+		{"MessageAttributeMap", map[string]string{"key": "{name}.entry.{n}.key", "value": "{name}.entry.{n}.value"}},
+		{"GetQueueAttributesResult", nil},
+	}
+	for _, test := range tests {
+		got := swagger.Components.Schemas[test.shapeName].Value.Extensions["x-aws-query-serialization"]
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected x-aws-query-serialization %v, got %v", test.shapeName, test.want, got)
+		}
+	}
+
+	// EC2 lists are never wrapped
+	swagger = testSchema(t, newTestAPI(t, "ec2", ec2Model))
+	if got := swagger.Components.Schemas["VpcList"].Value.Extensions["x-aws-query-serialization"]; got != "{name}.{n}" {
+		t.Errorf("VpcList: expected x-aws-query-serialization {name}.{n}, got %v", got)
+	}
+	// Only the query protocols serialize requests as form fields
+	swagger = testSchema(t, newTestAPI(t, "tagging", tagsModel))
+	if ext, found := swagger.Components.Schemas["TagMap"].Value.Extensions["x-aws-query-serialization"]; found {
+		t.Errorf("TagMap: expected no x-aws-query-serialization, got %v", ext)
+	}
+}
@@ -7,13 +7,126 @@
 package apimodel
 
 import (
-	oai "github.com/getkin/kin-openapi/openapi3"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gertd/go-pluralize"
+)
+
+// Prefixes of the names of the operations that create resources
+const (
+	createPrefix         = "Create"
+	createOrUpdatePrefix = "CreateOrUpdate"
 )
 
+// pluralizer converts the last word of resource names between singular and
+// plural
+var pluralizer = newPluralizer()
+
+// newPluralizer returns a pluralizer that also knows the plurals of the words
+// AWS APIs use differently from English, e.g. "Schemas" and not "Schemata",
+// or treat as singular, e.g. "DhcpOptions" or "LocationNfs"
+func newPluralizer() *pluralize.Client {
+	p := pluralize.NewClient()
+	p.AddIrregularRule("schema", "schemas")
+	for _, word := range []string{"efs", "nfs", "metadata", "options"} {
+		p.AddUncountableRule(word)
+	}
+	return p
+}
+
+// The types of the operations that manage a resource
+const (
+	OperationTypeCreate   = "Create"
+	OperationTypeReadOne  = "ReadOne"
+	OperationTypeReadMany = "ReadMany"
+	OperationTypeUpdate   = "Update"
+	OperationTypeDelete   = "Delete"
+)
+
+// OperationTypes are the types of the operations that manage a resource, in
+// CRUD order
+var OperationTypes = []string{
+	OperationTypeCreate,
+	OperationTypeReadOne,
+	OperationTypeReadMany,
+	OperationTypeUpdate,
+	OperationTypeDelete,
+}
+
+// Resource is a top-level object of an API, managed by the API's Create,
+// Read, Update and Delete operations
 type Resource struct {
+	// SingularName is the singular name of the resource, e.g. "Topic"
 	SingularName string
-	PluralName   string
-	createOp     *oai.Operation
+	// PluralName is the plural name of the resource, e.g. "Topics"
+	PluralName string
+	// Create is the operation that creates the resource
+	Create *Operation
+	// ReadOne is the operation that returns a single resource, e.g.
+	// "GetRestApi" or "GetTopicAttributes"
+	ReadOne *Operation
+	// ReadMany is the operation that returns a list of resources, e.g.
+	// "ListTopics" or "DescribeVpcs"
+	ReadMany *Operation
+	// Update is the operation that modifies the resource, e.g.
+	// "UpdateRestApi", "ModifyVpcAttribute" or "SetTopicAttributes"
+	Update *Operation
+	// Delete is the operation that deletes the resource
+	Delete *Operation
+	// Identifiers are the names of the members that identify the resource
+	// in the output of the Create operation, ARNs first, e.g. "TopicArn"
+	Identifiers []string
+	// PrimaryIdentifier is the identifier the Delete or ReadOne operations
+	// take, if it is one of the Identifiers, or else the first of the
+	// Identifiers
+	PrimaryIdentifier string
+	// ReadOneIdentifier is the name of the member of the ReadOne operation's
+	// input that identifies the resource
+	ReadOneIdentifier string
+	// DeleteIdentifier is the name of the member of the Delete operation's
+	// input that identifies the resource
+	DeleteIdentifier string
+	// Parent is the resource the resource is contained within, e.g. the
+	// RestApi of a Deployment in the AWS APIGateway API, or nil if the
+	// resource is a top-level resource
+	Parent *Resource
+	// Children are the resources contained within the resource
+	Children []*Resource
+	// createIdentifiers are the names of the members of the Create
+	// operation's input that identify the resource
+	createIdentifiers []string
+}
+
+// Operation returns the resource's operation of the supplied type or nil if
+// the resource has no such operation
+func (r *Resource) Operation(opType string) *Operation {
+	switch opType {
+	case OperationTypeCreate:
+		return r.Create
+	case OperationTypeReadOne:
+		return r.ReadOne
+	case OperationTypeReadMany:
+		return r.ReadMany
+	case OperationTypeUpdate:
+		return r.Update
+	case OperationTypeDelete:
+		return r.Delete
+	}
+	return nil
+}
+
+// MissingOperationTypes returns the types of the operations, in CRUD order,
+// that the API does not have for the resource
+func (r *Resource) MissingOperationTypes() []string {
+	res := []string{}
+	for _, opType := range OperationTypes {
+		if r.Operation(opType) == nil {
+			res = append(res, opType)
+		}
+	}
+	return res
 }
 
 // Many service APIs follow a pattern that we can use to determine top-level or
@@ -71,84 +184,389 @@ type Resource struct {
 //    }
 //  },
 
-//func getResources(api *API) (map[string]*Resource, error) {
-//	pluralize := pluralize.NewClient()
-//	resources := map[string]*Resource{}
-//	filter := &OperationFilter{
-//		Prefixes: []string{"Create"},
-//	}
-//	createOps := api.GetOperations(filter)
-//	for _, createOp := range createOps {
-//		// Some API operations are "CreateOrUpdate" -- i.e. "Replace". For
-//		// instance, the AWS Autoscaling API has a CreateOrUpdateTags
-//		// operation. Trim off the "CreateOrUpdate" prefix for these
-//		// operations.
-//		var objName string
-//		if strings.HasPrefix(createOp.Name, "CreateOrUpdate") {
-//			objName = strings.TrimPrefix(createOp.Name, "CreateOrUpdate")
-//		} else {
-//			objName = strings.TrimPrefix(createOp.Name, "Create")
-//		}
-//		singularName := pluralize.Singular(objName)
-//
-//		// Tag is a special case. It is often represented as a
-//		// top-level/resource object because there are CreateOrUpdateTags
-//		// operations that accept a payload that replaces all tags on a
-//		// specific resource. However, Tag is not an actual resource object.
-//		// Instead, nearly all resources can have zero or more key/value pairs
-//		// associated with them (these are tags).
-//		if singularName == "Tag" {
-//			continue
-//		}
-//
-//		pluralName := pluralize.Plural(objName)
-//		createOAIOp := oai.NewOperation()
-//		resource := &Resource{
-//			SingularName: singularName,
-//			PluralName:   pluralName,
-//		}
-//		// Find the shape representing the input to the create operation and
-//		// add a pointer to an oai.Schema describing the input shape
-//		if createOp.Input != nil {
-//			inShapeName := createOp.Input.Name
-//			inObj, found := api.objectMap[inShapeName]
-//			if !found {
-//				return nil, fmt.Errorf("expected to find input shape %s", inShapeName)
-//			}
-//			if inObj.DataType != "structure" {
-//				return nil, fmt.Errorf("expected to find a structure type for input shape %s but found %s", inShapeName, inObj.Type)
-//			}
-//			reqBody := oai.NewRequestBody().WithJSONSchema(inObj.Schema())
-//			createOAIOp.OperationID = createOp.Name
-//			createOAIOp.RequestBody = &oai.RequestBodyRef{Value: reqBody}
-//			api.swagger.AddOperation(createOp.opSpec.HTTP.RequestURI, createOp.opSpec.HTTP.Method, createOAIOp)
-//		}
-//		// Find the shape representing the ouput of the create operation and
-//		// add fields from the output shape to the resource object, excluding
-//		// fields already added from the input
-//		if createOp.Output != nil {
-//			outShapeName := createOp.Output.Name
-//			outObj, found := api.objectMap[outShapeName]
-//			if !found {
-//				return nil, fmt.Errorf("expected to find output shape %s", outShapeName)
-//			}
-//			if outObj.DataType != "structure" {
-//				return nil, fmt.Errorf("expected to find a structure type for output shape %s but found %s", outShapeName, outObj.Type)
-//			}
-//			successRespCode := 200
-//			if createOp.opSpec.HTTP.ResponseCode != nil {
-//				successRespCode = *createOp.opSpec.HTTP.ResponseCode
-//			}
-//			createOAIOp.AddResponse(successRespCode, oai.NewResponse().WithJSONSchema(outObj.Schema()))
-//
-//			if len(createOp.Errors) > 0 {
-//				// TODO(jaypipes): for each error shape, add a response to the
-//				// Responses object
-//				continue
-//			}
-//		}
-//		resource.createOp = createOAIOp
-//		resources[objName] = resource
-//	}
-//	return resources, nil
-//}
+// GetResources returns the API's resources, sorted by name. A resource is
+// inferred from each Create{$ObjectName} or CreateOrUpdate{$ObjectName}
+// operation, e.g. "CreateOrUpdateTags" in the AWS Autoscaling API, which
+// "replaces" the object. Tags are not resources.
+func (a *API) GetResources() []*Resource {
+	resources := map[string]*Resource{}
+	filter := &OperationFilter{Prefixes: []string{createPrefix}}
+	for _, createOp := range a.GetOperations(filter) {
+		objName := strings.TrimPrefix(createOp.Name, createOrUpdatePrefix)
+		if objName == createOp.Name {
+			objName = strings.TrimPrefix(createOp.Name, createPrefix)
+		}
+		// Skip operations like "Create" or "Created..." that do not name an
+		// object
+		if objName == "" || !unicode.IsUpper([]rune(objName)[0]) {
+			continue
+		}
+		// Some operations create an object along with others, e.g.
+		// "CreateDistributionWithTags" in the AWS CloudFront API
+		if x := strings.Index(objName, "With"); x > 0 &&
+			len(objName) > x+4 && unicode.IsUpper([]rune(objName[x+4:])[0]) {
+			objName = objName[:x]
+		}
+		singularName := singularName(objName)
+		// Tag is a special case. It is often represented as a
+		// top-level/resource object because there are CreateOrUpdateTags
+		// operations that accept a payload that replaces all tags on a
+		// specific resource. However, Tag is not an actual resource object.
+		// Instead, nearly all resources can have zero or more key/value pairs
+		// associated with them (these are tags).
+		if singularName == "Tag" {
+			continue
+		}
+		// When several operations create the same object, e.g. "CreateTopic"
+		// and "CreateTopics", prefer the one named after a single object
+		if res, found := resources[singularName]; found &&
+			res.Create.Name == createPrefix+singularName {
+			continue
+		}
+		resources[singularName] = &Resource{
+			SingularName: singularName,
+			PluralName:   pluralName(singularName),
+			Create:       createOp,
+		}
+	}
+	// Some operations create another resource's object in a different way,
+	// e.g. "CreateDBInstanceReadReplica" in the AWS RDS API, which returns a
+	// DBInstance. These are not resources of their own.
+	created := map[string]*Resource{}
+	for name, resource := range resources {
+		if other := a.createdResource(resource, resources); other != nil {
+			created[name] = other
+		}
+	}
+	for name, other := range created {
+		if _, found := created[other.SingularName]; !found {
+			delete(resources, name)
+		}
+	}
+	res := make([]*Resource, 0, len(resources))
+	for _, resource := range resources {
+		a.addOperations(resource)
+		a.addIdentifiers(resource)
+		res = append(res, resource)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].SingularName < res[j].SingularName
+	})
+	a.addHierarchy(res)
+	return res
+}
+
+// createdResource returns the other resource whose object the resource's
+// Create operation returns, i.e. whose shape is the Create operation's output
+// shape or one of its members, or nil if there is no such resource
+func (a *API) createdResource(r *Resource, resources map[string]*Resource) *Resource {
+	if r.Create.Output == nil {
+		return nil
+	}
+	shapeNames := []string{r.Create.Output.ShapeName}
+	if outShape, found := a.apiSpec.Shapes[r.Create.Output.ShapeName]; found {
+		for _, ref := range outShape.Members {
+			if ref.ShapeName != nil && !a.isListShape(ref) {
+				shapeNames = append(shapeNames, *ref.ShapeName)
+			}
+		}
+	}
+	for _, shapeName := range shapeNames {
+		if other, found := resources[shapeName]; found && other != r {
+			return other
+		}
+	}
+	return nil
+}
+
+// addOperations sets the resource's Read, Update and Delete operations. The
+// operations are found by name, e.g. "DescribeTopic" or "GetTopicAttributes"
+// for the ReadOne operation of a Topic, and must agree with the shapes of
+// their input and output: ReadOne operations return a single resource,
+// Update operations take the identifier of a single resource, or return a
+// single resource, ReadMany operations return a list and Delete operations
+// take an input. Many APIs have no operation reading a single resource and
+// read one with the ReadMany operation instead, e.g. DescribeDBInstances with
+// a DBInstanceIdentifier in the AWS RDS API.
+func (a *API) addOperations(r *Resource) {
+	s, p := r.SingularName, r.PluralName
+	readsOne := func(op *Operation) bool {
+		return a.identifiesOne(op) || a.returnsMember(op, s)
+	}
+	r.ReadOne = a.findOperation(
+		[]string{
+			"Get" + s, "Describe" + s,
+			"Get" + s + "Attributes", "Describe" + s + "Attributes",
+		},
+		func(op *Operation) bool {
+			return a.describesResource(op, s)
+		},
+	)
+	if r.ReadOne == nil {
+		r.ReadOne = a.findOperation(
+			[]string{"Describe" + p},
+			func(op *Operation) bool {
+				return a.returnsList(op) && a.acceptsIdentifier(op, s)
+			},
+		)
+	}
+	r.ReadMany = a.findOperation(
+		[]string{"List" + p, "Describe" + p, "Get" + p},
+		func(op *Operation) bool {
+			// The operations reading one and many resources have the same
+			// name when the resource's name is the same in the singular and
+			// plural, e.g. "DescribeDhcpOptions"
+			return a.returnsList(op) && (s != p || !readsOne(op))
+		},
+	)
+	r.Update = a.findOperation(
+		[]string{
+			"Update" + s, "Modify" + s, "Put" + s,
+			"Update" + s + "Attributes", "Set" + s + "Attributes",
+			"Modify" + s + "Attributes", "Modify" + s + "Attribute",
+			"Update" + s + "Configuration",
+		},
+		readsOne,
+	)
+	// A CreateOrUpdate operation updates the resource too
+	if r.Update == nil && strings.HasPrefix(r.Create.Name, createOrUpdatePrefix) {
+		r.Update = r.Create
+	}
+	r.Delete = a.findOperation(
+		[]string{"Delete" + s, "Delete" + p},
+		func(op *Operation) bool {
+			return op.Input != nil
+		},
+	)
+}
+
+// findOperation returns the first of the named operations that matches the
+// supplied function or nil if none do
+func (a *API) findOperation(opNames []string, matches func(*Operation) bool) *Operation {
+	for _, opName := range opNames {
+		if op := a.GetOperation(opName); op != nil && matches(op) {
+			return op
+		}
+	}
+	return nil
+}
+
+// identifierMembers returns the names of the members of the operation's input
+// shape that identify the objects the operation acts on: the required members
+// and those bound to the request URI
+func (a *API) identifierMembers(op *Operation) []string {
+	res := []string{}
+	if op.Input == nil {
+		return res
+	}
+	inShape, found := a.apiSpec.Shapes[op.Input.ShapeName]
+	if !found {
+		return res
+	}
+	for memberName, ref := range inShape.Members {
+		if inStrings(memberName, inShape.Required) || memberLocation(ref) == locationURI {
+			res = append(res, memberName)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// identifiesOne returns true if the operation acts on a single object, i.e.
+// at least one of its identifier members is not a list
+func (a *API) identifiesOne(op *Operation) bool {
+	memberNames := a.identifierMembers(op)
+	if len(memberNames) == 0 {
+		return false
+	}
+	inShape := a.apiSpec.Shapes[op.Input.ShapeName]
+	for _, memberName := range memberNames {
+		if !a.isListShape(inShape.Members[memberName]) {
+			return true
+		}
+	}
+	return false
+}
+
+// returnsList returns true if the operation is paginated or its output shape
+// has a list member
+func (a *API) returnsList(op *Operation) bool {
+	if op.Paginated {
+		return true
+	}
+	if op.Output == nil {
+		return false
+	}
+	outShape, found := a.apiSpec.Shapes[op.Output.ShapeName]
+	if !found {
+		return false
+	}
+	for _, ref := range outShape.Members {
+		if a.isListShape(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// returnsMember returns true if the operation's output shape has a member,
+// other than a list, with the supplied name or of the shape with the supplied
+// name, e.g. the "User" member of the GetUserResponse shape in the AWS IAM API
+func (a *API) returnsMember(op *Operation, name string) bool {
+	if op.Output == nil {
+		return false
+	}
+	outShape, found := a.apiSpec.Shapes[op.Output.ShapeName]
+	if !found {
+		return false
+	}
+	for memberName, ref := range outShape.Members {
+		if a.isListShape(ref) {
+			continue
+		}
+		if memberName == name || (ref.ShapeName != nil && *ref.ShapeName == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// describesResource returns true if the operation's output describes a
+// single named resource: the output shape is named after the resource, e.g.
+// the RestApi shape returned by GetRestApi in the AWS APIGateway API, it has
+// a member named after the resource or a structure identifying the resource,
+// e.g. the Configuration member of the GetFunctionResponse shape in the AWS
+// Lambda API, or it has the attributes returned by operations like
+// GetTopicAttributes in the AWS SNS API. Otherwise the output's members must
+// be the resource's own, e.g. those of the GetApiResponse shape in the AWS
+// ApiGatewayV2 API, so the operation must identify a single resource.
+func (a *API) describesResource(op *Operation, name string) bool {
+	if op.Output == nil {
+		return false
+	}
+	if op.Output.ShapeName == name || a.returnsMember(op, name) {
+		return true
+	}
+	outShape, found := a.apiSpec.Shapes[op.Output.ShapeName]
+	if !found {
+		return false
+	}
+	attributes := strings.HasSuffix(op.Name, "Attributes")
+	for _, ref := range outShape.Members {
+		if ref.ShapeName == nil {
+			continue
+		}
+		shape, found := a.apiSpec.Shapes[*ref.ShapeName]
+		if !found {
+			continue
+		}
+		switch shape.Type {
+		case "structure":
+			if attributes || len(a.shapeIdentifiers(*ref.ShapeName, name, false)) > 0 {
+				return true
+			}
+		case "map":
+			if attributes {
+				return true
+			}
+		}
+	}
+	return !attributes && a.identifiesOne(op)
+}
+
+// acceptsIdentifier returns true if the operation's input has a member, or a
+// list member, that identifies the named resource, e.g. the GroupIds member
+// of the DescribeSecurityGroupsRequest shape in the AWS EC2 API
+func (a *API) acceptsIdentifier(op *Operation, name string) bool {
+	if op.Input == nil {
+		return false
+	}
+	return len(a.shapeIdentifiers(op.Input.ShapeName, name, false)) > 0 ||
+		len(a.listIdentifiers(op.Input.ShapeName, name)) > 0
+}
+
+// isListShape returns true if the referenced shape is a list
+func (a *API) isListShape(ref *shapeRefSpec) bool {
+	if ref == nil || ref.ShapeName == nil {
+		return false
+	}
+	shape, found := a.apiSpec.Shapes[*ref.ShapeName]
+	return found && shape.Type == "list"
+}
+
+// GetTopLevelResources returns the API's resources that are not contained
+// within another resource, sorted by name
+func (a *API) GetTopLevelResources() []*Resource {
+	res := []*Resource{}
+	for _, resource := range a.GetResources() {
+		if resource.Parent == nil {
+			res = append(res, resource)
+		}
+	}
+	return res
+}
+
+// GetResource returns the resource with the supplied singular or plural name
+// or nil if the API has no such resource
+func (a *API) GetResource(name string) *Resource {
+	for _, resource := range a.GetResources() {
+		if resource.SingularName == name || resource.PluralName == name {
+			return resource
+		}
+	}
+	return nil
+}
+
+// singularName returns the singular form of a CamelCased object name, e.g.
+// "UsagePlanKey" for "UsagePlanKeys"
+func singularName(name string) string {
+	leading, last := splitLastWord(name)
+	if isAcronym(strings.TrimSuffix(last, "s")) {
+		return leading + strings.TrimSuffix(last, "s")
+	}
+	return leading + pluralizer.Singular(last)
+}
+
+// pluralName returns the plural form of a CamelCased object name, e.g.
+// "RestApis" for "RestApi"
+func pluralName(name string) string {
+	leading, last := splitLastWord(name)
+	if isAcronym(last) {
+		return leading + last + "s"
+	}
+	if isAcronym(strings.TrimSuffix(last, "s")) {
+		return name
+	}
+	return leading + pluralizer.Plural(last)
+}
+
+// splitLastWord splits a CamelCased name into its leading words and its last
+// word, which is all that is pluralized because the pluralizer lowercases all
+// but the first letter of a word, e.g. "Restapis" for "RestApi". An acronym
+// followed by a plural "s", e.g. "VPCs", is a single word.
+func splitLastWord(name string) (string, string) {
+	runes := []rune(name)
+	for x := len(runes) - 1; x > 0; x-- {
+		if !unicode.IsUpper(runes[x]) {
+			continue
+		}
+		if unicode.IsLower(runes[x-1]) {
+			return string(runes[:x]), string(runes[x:])
+		}
+		// The last capital of an acronym starts a word when followed by
+		// lowercase letters, e.g. "DBInstance"
+		rest := string(runes[x+1:])
+		if rest != "" && rest != "s" && unicode.IsLower(runes[x+1]) {
+			return string(runes[:x]), string(runes[x:])
+		}
+	}
+	return "", name
+}
+
+// isAcronym returns true if the supplied word is an acronym, e.g. "VPC"
+func isAcronym(word string) bool {
+	if len(word) < 2 {
+		return false
+	}
+	return strings.ToUpper(word) == word && strings.ToLower(word) != word
+}
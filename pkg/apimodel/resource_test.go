@@ -0,0 +1,261 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"strings"
+	"testing"
+)
+
+const ec2Model = `{
+  "metadata": {
+    "apiVersion": "2016-11-15",
+    "endpointPrefix": "ec2",
+    "protocol": "ec2",
+    "serviceFullName": "Amazon Elastic Compute Cloud",
+    "serviceId": "EC2",
+    "signatureVersion": "v4"
+  },
+  "operations": {
+    "CreateVpc": {
+      "name": "CreateVpc",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateVpcRequest"},
+      "output": {"shape": "CreateVpcResult"}
+    },
+    "CreateDefaultVpc": {
+      "name": "CreateDefaultVpc",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateDefaultVpcRequest"},
+      "output": {"shape": "CreateDefaultVpcResult"}
+    },
+    "DescribeVpcAttribute": {
+      "name": "DescribeVpcAttribute",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeVpcAttributeRequest"},
+      "output": {"shape": "DescribeVpcAttributeResult"}
+    },
+    "DescribeVpcs": {
+      "name": "DescribeVpcs",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeVpcsRequest"},
+      "output": {"shape": "DescribeVpcsResult"}
+    },
+    "DeleteVpc": {
+      "name": "DeleteVpc",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DeleteVpcRequest"}
+    },
+    "CreateSubnet": {
+      "name": "CreateSubnet",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateSubnetRequest"},
+      "output": {"shape": "CreateSubnetResult"}
+    },
+    "CreateSecurityGroup": {
+      "name": "CreateSecurityGroup",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "CreateSecurityGroupRequest"},
+      "output": {"shape": "CreateSecurityGroupResult"}
+    },
+    "DescribeSecurityGroups": {
+      "name": "DescribeSecurityGroups",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeSecurityGroupsRequest"},
+      "output": {"shape": "DescribeSecurityGroupsResult"}
+    },
+    "DeleteSecurityGroup": {
+      "name": "DeleteSecurityGroup",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DeleteSecurityGroupRequest"}
+    }
+  },
+  "shapes": {
+    "CreateVpcRequest": {
+      "type": "structure",
+      "required": ["CidrBlock"],
+      "members": {"CidrBlock": {"shape": "String"}}
+    },
+    "CreateVpcResult": {
+      "type": "structure",
+      "members": {"Vpc": {"shape": "Vpc"}}
+    },
+    "CreateDefaultVpcRequest": {
+      "type": "structure",
+      "members": {"DryRun": {"shape": "Boolean"}}
+    },
+    "CreateDefaultVpcResult": {
+      "type": "structure",
+      "members": {"Vpc": {"shape": "Vpc"}}
+    },
+    "Vpc": {
+      "type": "structure",
+      "members": {
+        "CidrBlock": {"shape": "String"},
+        "VpcId": {"shape": "String"}
+      }
+    },
+    "VpcList": {"type": "list", "member": {"shape": "Vpc"}},
+    "VpcIdStringList": {"type": "list", "member": {"shape": "String"}},
+    "DescribeVpcAttributeRequest": {
+      "type": "structure",
+      "required": ["Attribute", "VpcId"],
+      "members": {
+        "Attribute": {"shape": "String"},
+        "VpcId": {"shape": "String"}
+      }
+    },
+    "DescribeVpcAttributeResult": {
+      "type": "structure",
+      "members": {
+        "VpcId": {"shape": "String"},
+        "EnableDnsSupport": {"shape": "AttributeBooleanValue"}
+      }
+    },
+    "AttributeBooleanValue": {
+      "type": "structure",
+      "members": {"Value": {"shape": "Boolean"}}
+    },
+    "DescribeVpcsRequest": {
+      "type": "structure",
+      "members": {"VpcIds": {"shape": "VpcIdStringList"}}
+    },
+    "DescribeVpcsResult": {
+      "type": "structure",
+      "members": {"Vpcs": {"shape": "VpcList"}}
+    },
+    "DeleteVpcRequest": {
+      "type": "structure",
+      "required": ["VpcId"],
+      "members": {"VpcId": {"shape": "String"}}
+    },
+    "CreateSubnetRequest": {
+      "type": "structure",
+      "required": ["CidrBlock", "VpcId"],
+      "members": {
+        "CidrBlock": {"shape": "String"},
+        "VpcId": {"shape": "String"}
+      }
+    },
+    "CreateSubnetResult": {
+      "type": "structure",
+      "members": {"Subnet": {"shape": "Subnet"}}
+    },
+    "Subnet": {
+      "type": "structure",
+      "members": {
+        "SubnetId": {"shape": "String"},
+        "VpcId": {"shape": "String"}
+      }
+    },
+    "CreateSecurityGroupRequest": {
+      "type": "structure",
+      "required": ["GroupName"],
+      "members": {
+        "GroupName": {"shape": "String"},
+        "VpcId": {"shape": "String"}
+      }
+    },
+    "CreateSecurityGroupResult": {
+      "type": "structure",
+      "members": {"GroupId": {"shape": "String"}}
+    },
+    "DescribeSecurityGroupsRequest": {
+      "type": "structure",
+      "members": {
+        "GroupIds": {"shape": "GroupIdStringList"},
+        "GroupNames": {"shape": "GroupNameStringList"}
+      }
+    },
+    "DescribeSecurityGroupsResult": {
+      "type": "structure",
+      "members": {"SecurityGroups": {"shape": "SecurityGroupList"}}
+    },
+    "SecurityGroup": {
+      "type": "structure",
+      "members": {
+        "GroupId": {"shape": "String"},
+        "GroupName": {"shape": "String"}
+      }
+    },
+    "SecurityGroupList": {"type": "list", "member": {"shape": "SecurityGroup"}},
+    "GroupIdStringList": {"type": "list", "member": {"shape": "String"}},
+    "GroupNameStringList": {"type": "list", "member": {"shape": "String"}},
+    "DeleteSecurityGroupRequest": {
+      "type": "structure",
+      "members": {
+        "GroupId": {"shape": "String"},
+        "GroupName": {"shape": "String"}
+      }
+    },
+    "Boolean": {"type": "boolean"},
+    "String": {"type": "string"}
+  }
+}`
+
+func TestGetResources(t *testing.T) {
+	api := newTestAPI(t, "ec2", ec2Model)
+	// CreateDefaultVpc returns a Vpc, so DefaultVpc is not a resource
+	want := []string{"SecurityGroup", "Subnet", "Vpc"}
+	got := []string{}
+	for _, r := range api.GetResources() {
+		got = append(got, r.SingularName)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected resources %v, got %v", want, got)
+	}
+}
+
+// operationName returns the name of the supplied operation or the empty
+// string if it is nil
+func operationName(op *Operation) string {
+	if op == nil {
+		return ""
+	}
+	return op.Name
+}
+
+func TestResourceOperations(t *testing.T) {
+	api := newTestAPI(t, "ec2", ec2Model)
+	tests := []struct {
+		resource          string
+		readOne           string
+		readOneIdentifier string
+		readMany          string
+		delete            string
+		deleteIdentifier  string
+	}{
+		// DescribeVpcAttribute returns a single attribute, not the Vpc, so
+		// the Vpc is read with DescribeVpcs
+		{"Vpc", "DescribeVpcs", "VpcIds", "DescribeVpcs", "DeleteVpc", "VpcId"},
+		{"SecurityGroup", "DescribeSecurityGroups", "GroupIds", "DescribeSecurityGroups", "DeleteSecurityGroup", "GroupId"},
+		{"Subnet", "", "", "", "", ""},
+	}
+	for _, test := range tests {
+		r := api.GetResource(test.resource)
+		if r == nil {
+			t.Fatalf("expected resource %s", test.resource)
+		}
+		got := []string{
+			operationName(r.ReadOne), r.ReadOneIdentifier, operationName(r.ReadMany),
+			operationName(r.Delete), r.DeleteIdentifier,
+		}
+		want := []string{
+			test.readOne, test.readOneIdentifier, test.readMany,
+			test.delete, test.deleteIdentifier,
+		}
+		for x := range want {
+			if got[x] != want[x] {
+				t.Errorf("%s: expected ReadOne, ReadOneIdentifier, ReadMany, Delete, DeleteIdentifier %v, got %v", test.resource, want, got)
+				break
+			}
+		}
+		if mismatches := r.IdentifierMismatches(); len(mismatches) != 0 {
+			t.Errorf("%s: expected no identifier mismatches, got %d", test.resource, len(mismatches))
+		}
+	}
+}
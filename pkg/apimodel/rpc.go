@@ -0,0 +1,277 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"sort"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// RPC styles describe how the operations of RPC-style APIs, which send every
+// operation as a POST to "/", are represented in the OpenAPI schema
+const (
+	// RPCStyleFragment gives each operation its own path, with the operation
+	// name appended to "/" as a fragment, e.g. "/#CreateTopic". OpenAPI paths
+	// may not contain fragments, but each operation is kept separate.
+	RPCStyleFragment = "fragment"
+	// RPCStyleHeader describes all of the API's operations with a single
+	// POST operation on "/" whose X-Amz-Target header (json protocol) or
+	// Action form field (query protocols) selects the AWS operation. The
+	// request and response bodies are a oneOf of the bodies of each AWS
+	// operation.
+	RPCStyleHeader = "header"
+	// RPCStyleCallback is RPCStyleHeader with each AWS operation also fully
+	// described as a callback of the single POST operation, keyed by
+	// operation name
+	RPCStyleCallback = "callback"
+)
+
+// RPCStyles are the supported RPC styles
+var RPCStyles = []string{RPCStyleFragment, RPCStyleHeader, RPCStyleCallback}
+
+// headerAmzTarget is the HTTP header that selects the operation of a json
+// protocol request
+const headerAmzTarget = "X-Amz-Target"
+
+// rpcDispatchOperationID is the operation ID of the single operation
+// describing all of an RPC-style API's operations
+const rpcDispatchOperationID = "Dispatch"
+
+// isRPC returns true if the API sends every operation as a POST to "/" and
+// selects the operation with a header or form field
+func (m *metadataSpec) isRPC() bool {
+	return m.Protocol == ProtocolJSON || m.isQuery()
+}
+
+// target returns the X-Amz-Target header value selecting the supplied
+// operation of a json protocol API, or the empty string if the API does not
+// use the header
+func (m *metadataSpec) target(opName string) string {
+	if m.Protocol != ProtocolJSON || m.TargetPrefix == "" {
+		return ""
+	}
+	return m.TargetPrefix + "." + opName
+}
+
+// targetParameter returns the required X-Amz-Target header parameter whose
+// only allowed values are the supplied targets
+func targetParameter(targets ...string) *oai.ParameterRef {
+	values := make([]interface{}, len(targets))
+	for x, target := range targets {
+		values[x] = target
+	}
+	param := oai.NewHeaderParameter(headerAmzTarget).WithRequired(true)
+	param.Schema = oai.NewSchemaRef("", oai.NewStringSchema().WithEnum(values...))
+	return &oai.ParameterRef{Value: param}
+}
+
+// rpcDispatchOperation returns the single OpenAPI operation describing all of
+// the supplied operations of an RPC-style API, keyed by operation name. The
+// operation's x-aws-operations extension maps each operation name to an
+// rpcOperationEntry, and the examples of each operation's bodies are merged
+// into the dispatch operation's bodies. When operations override the API's
+// security requirement, the dispatch operation accepts any of the API's and
+// the operations' requirements. With the callback RPC style, each operation
+// is also added as a callback.
+func rpcDispatchOperation(
+	ops map[string]*oai.Operation,
+	meta *metadataSpec,
+	apiSecurity oai.SecurityRequirements,
+	rpcStyle string,
+) *oai.Operation {
+	opNames := make([]string, 0, len(ops))
+	for opName := range ops {
+		opNames = append(opNames, opName)
+	}
+	sort.Strings(opNames)
+
+	dispatch := oai.NewOperation()
+	dispatch.OperationID = rpcDispatchOperationID
+	dispatch.Responses = oai.NewResponses()
+	entries := map[string]map[string]interface{}{}
+	targets := []string{}
+	security := append(oai.SecurityRequirements{}, apiSecurity...)
+	overridden := false
+	reqSchemas := newSchemaSet()
+	respSchemas := map[string]*schemaSet{}
+	for _, opName := range opNames {
+		op := ops[opName]
+		selector := opName
+		if target := meta.target(opName); target != "" {
+			selector = target
+			targets = append(targets, target)
+		}
+		entries[opName] = rpcOperationEntry(op, selector)
+		if op.Security != nil {
+			overridden = true
+			security = appendSecurityAlternatives(security, *op.Security)
+		}
+		if op.RequestBody != nil {
+			reqSchemas.addContent(op.RequestBody.Value.Content)
+		}
+		for code, respRef := range op.Responses {
+			if respRef.Ref != "" {
+				// Shared component responses like the default error response
+				// are the same for every operation
+				dispatch.Responses[code] = respRef
+				continue
+			}
+			if _, found := respSchemas[code]; !found {
+				respSchemas[code] = newSchemaSet()
+			}
+			respSchemas[code].addContent(respRef.Value.Content)
+		}
+		if rpcStyle == RPCStyleCallback {
+			if dispatch.Callbacks == nil {
+				dispatch.Callbacks = map[string]*oai.CallbackRef{}
+			}
+			pathItem := &oai.PathItem{}
+			pathItem.SetOperation("POST", op)
+			dispatch.Callbacks[opName] = &oai.CallbackRef{
+				Value: &oai.Callback{"{$url}": pathItem},
+			}
+		}
+	}
+	if overridden {
+		dispatch.Security = &security
+	}
+	if len(targets) > 0 {
+		dispatch.Parameters = oai.Parameters{targetParameter(targets...)}
+	}
+	if len(reqSchemas.refs) > 0 {
+		reqBody := oai.NewRequestBody().WithRequired(true).WithContent(reqSchemas.oneOfContent())
+		dispatch.RequestBody = &oai.RequestBodyRef{Value: reqBody}
+	}
+	for code, schemas := range respSchemas {
+		dispatch.Responses[code] = &oai.ResponseRef{
+			Value: oai.NewResponse().WithContent(schemas.oneOfContent()),
+		}
+	}
+	if dispatch.Responses.Get(200) == nil {
+		dispatch.AddResponse(200, oai.NewResponse())
+	}
+	addExtension(&dispatch.ExtensionProps, "x-aws-operations", entries)
+	return dispatch
+}
+
+// rpcOperationEntry returns the x-aws-operations entry of an operation merged
+// into an RPC dispatch operation. The entry contains the X-Amz-Target header
+// or Action field value selecting the operation and what the dispatch
+// operation cannot describe for each operation separately: its description,
+// deprecation, security requirement and extensions, e.g. x-aws-pagination.
+func rpcOperationEntry(op *oai.Operation, selector string) map[string]interface{} {
+	entry := map[string]interface{}{"selector": selector}
+	if op.Description != "" {
+		entry["description"] = op.Description
+	}
+	if op.Deprecated {
+		entry["deprecated"] = true
+	}
+	if op.Security != nil {
+		entry["security"] = *op.Security
+	}
+	for key, value := range op.Extensions {
+		entry[key] = value
+	}
+	return entry
+}
+
+// appendSecurityAlternatives appends to a list of alternative security
+// requirements those of the supplied operation's requirements it does not
+// already contain. An operation without any security requirement may be
+// called anonymously, which is the empty requirement.
+func appendSecurityAlternatives(
+	security oai.SecurityRequirements,
+	opSecurity oai.SecurityRequirements,
+) oai.SecurityRequirements {
+	if len(opSecurity) == 0 {
+		opSecurity = oai.SecurityRequirements{oai.NewSecurityRequirement()}
+	}
+	for _, req := range opSecurity {
+		found := false
+		for _, existing := range security {
+			if reflect.DeepEqual(existing, req) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			security = append(security, req)
+		}
+	}
+	return security
+}
+
+// schemaSet collects the distinct schemas and the examples, keyed by media
+// type, of the request or response bodies of the operations merged into an
+// RPC dispatch operation
+type schemaSet struct {
+	refs map[string][]*oai.SchemaRef
+	// seen contains the JSON of each collected schema, keyed by media type
+	seen     map[string]map[string]bool
+	examples map[string]map[string]*oai.ExampleRef
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{
+		refs:     map[string][]*oai.SchemaRef{},
+		seen:     map[string]map[string]bool{},
+		examples: map[string]map[string]*oai.ExampleRef{},
+	}
+}
+
+// addContent adds the schema and examples of each media type in the supplied
+// content to the set. The alternatives of a oneOf schema are added
+// individually.
+func (set *schemaSet) addContent(content oai.Content) {
+	for mediaType, mt := range content {
+		if mt.Schema == nil {
+			continue
+		}
+		for name, example := range mt.Examples {
+			if _, found := set.examples[mediaType]; !found {
+				set.examples[mediaType] = map[string]*oai.ExampleRef{}
+			}
+			set.examples[mediaType][name] = example
+		}
+		refs := []*oai.SchemaRef{mt.Schema}
+		if mt.Schema.Ref == "" && len(mt.Schema.Value.OneOf) > 0 {
+			refs = mt.Schema.Value.OneOf
+		}
+		if _, found := set.seen[mediaType]; !found {
+			set.seen[mediaType] = map[string]bool{}
+		}
+		for _, ref := range refs {
+			b, err := ref.MarshalJSON()
+			if err != nil || set.seen[mediaType][string(b)] {
+				continue
+			}
+			set.seen[mediaType][string(b)] = true
+			set.refs[mediaType] = append(set.refs[mediaType], ref)
+		}
+	}
+}
+
+// oneOfContent returns OpenAPI content whose media types are described by a
+// oneOf the set's schemas for the media type and have the set's examples for
+// the media type
+func (set *schemaSet) oneOfContent() oai.Content {
+	content := oai.NewContent()
+	for mediaType, refs := range set.refs {
+		schemaRef := refs[0]
+		if len(refs) > 1 {
+			schemaRef = oai.NewSchemaRef("", &oai.Schema{OneOf: refs})
+		}
+		content[mediaType] = oai.NewMediaType().WithSchemaRef(schemaRef)
+		if examples := set.examples[mediaType]; len(examples) > 0 {
+			content[mediaType].Examples = examples
+		}
+	}
+	return content
+}
@@ -0,0 +1,156 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+func TestRPCStyleHeader(t *testing.T) {
+	api := newTestAPI(t, "cognito-identity", cognitoIdentityModel)
+	opts := DefaultSchemaOptions()
+	opts.RPCStyle = RPCStyleHeader
+	swagger, err := api.SchemaWithOptions(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(swagger.Paths) != 1 {
+		t.Fatalf("expected a single path, got %d", len(swagger.Paths))
+	}
+	path, dispatch := findOperation(t, swagger, rpcDispatchOperationID)
+	if path != "/" {
+		t.Errorf("expected the dispatch operation on /, got %s", path)
+	}
+	// Only the callback RPC style describes each AWS operation in full
+	if len(dispatch.Callbacks) != 0 {
+		t.Errorf("expected no callbacks, got %d", len(dispatch.Callbacks))
+	}
+	entries, ok := dispatch.Extensions["x-aws-operations"].(map[string]map[string]interface{})
+	if !ok {
+		t.Fatalf("expected x-aws-operations, got %v", dispatch.Extensions["x-aws-operations"])
+	}
+	want := map[string]string{
+		"DescribeIdentity": "AWSCognitoIdentityService.DescribeIdentity",
+		"GetId":            "AWSCognitoIdentityService.GetId",
+	}
+	for opName, target := range want {
+		if selector := entries[opName]["selector"]; selector != target {
+			t.Errorf("expected %s to be selected by %s, got %v", opName, target, selector)
+		}
+	}
+
+	// GetId may be called anonymously, so its security requirement is
+	// carried in its entry and the dispatch operation accepts both signed
+	// and anonymous requests
+	security, ok := entries["GetId"]["security"].(oai.SecurityRequirements)
+	if !ok || len(security) != 0 {
+		t.Errorf("expected the GetId entry to have security: [], got %v", entries["GetId"]["security"])
+	}
+	if authType := entries["GetId"]["x-aws-auth-type"]; authType != AuthTypeNone {
+		t.Errorf("expected the GetId entry to have x-aws-auth-type %s, got %v", AuthTypeNone, authType)
+	}
+	if security, found := entries["DescribeIdentity"]["security"]; found {
+		t.Errorf("expected the DescribeIdentity entry to use the API's security, got %v", security)
+	}
+	wantSecurity := oai.SecurityRequirements{
+		oai.NewSecurityRequirement().Authenticate(securitySchemeSigV4),
+		oai.NewSecurityRequirement(),
+	}
+	if dispatch.Security == nil || !reflect.DeepEqual(*dispatch.Security, wantSecurity) {
+		t.Errorf("expected the dispatch security %v, got %v", wantSecurity, dispatch.Security)
+	}
+}
+
+func TestRPCDispatchOperationDetails(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:      dynamodbTablesModel,
+		model.DocsFile:       dynamodbDocs,
+		model.ExamplesFile:   dynamodbExamples,
+		model.PaginatorsFile: dynamodbPaginators,
+		model.WaitersFile:    dynamodbWaiters,
+	})
+	opts := DefaultSchemaOptions()
+	opts.RPCStyle = RPCStyleHeader
+	swagger, err := api.SchemaWithOptions(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, dispatch := findOperation(t, swagger, rpcDispatchOperationID)
+	if dispatch.Security != nil {
+		t.Errorf("expected the API's security, got %v", *dispatch.Security)
+	}
+	entries := dispatch.Extensions["x-aws-operations"].(map[string]map[string]interface{})
+	describeTable := entries["DescribeTable"]
+	if desc := describeTable["description"]; desc != "Returns information about the table. See ListTables." {
+		t.Errorf("expected the DescribeTable description, got %v", desc)
+	}
+	if _, found := describeTable["x-aws-waiters"]; !found {
+		t.Errorf("expected the DescribeTable entry to have x-aws-waiters, got %v", describeTable)
+	}
+	listTables := entries["ListTables"]
+	if _, found := listTables["description"]; found {
+		t.Errorf("expected no ListTables description, got %v", listTables["description"])
+	}
+	pagination, _ := listTables["x-aws-pagination"].(map[string]interface{})
+	if pagination["limitKey"] != "Limit" {
+		t.Errorf("expected the ListTables entry to have x-aws-pagination, got %v", listTables)
+	}
+
+	// The examples of every operation are merged into the dispatch bodies
+	reqExamples := mediaTypeOf(t, dispatch.RequestBody.Value.Content).Examples
+	for _, name := range []string{"DescribeTable-1", "to-list-all-tables-1472059565734"} {
+		if reqExamples[name] == nil {
+			t.Errorf("expected request example %s, got %v", name, reqExamples)
+		}
+	}
+	respExamples := mediaTypeOf(t, dispatch.Responses["200"].Value.Content).Examples
+	if len(respExamples) != 1 || respExamples["to-list-all-tables-1472059565734"] == nil {
+		t.Errorf("expected the ListTables response example, got %v", respExamples)
+	}
+}
+
+func TestRPCStyleCallback(t *testing.T) {
+	api := newTestAPI(t, "cognito-identity", cognitoIdentityModel)
+	opts := DefaultSchemaOptions()
+	opts.RPCStyle = RPCStyleCallback
+	swagger, err := api.SchemaWithOptions(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(swagger.Paths) != 1 {
+		t.Fatalf("expected a single path, got %d", len(swagger.Paths))
+	}
+	_, dispatch := findOperation(t, swagger, rpcDispatchOperationID)
+	if len(dispatch.Callbacks) != 2 {
+		t.Fatalf("expected 2 callbacks, got %d", len(dispatch.Callbacks))
+	}
+	for _, opName := range []string{"DescribeIdentity", "GetId"} {
+		callback := dispatch.Callbacks[opName]
+		if callback == nil || (*callback.Value)["{$url}"] == nil {
+			t.Errorf("expected a callback for %s, got %v", opName, callback)
+			continue
+		}
+		op := (*callback.Value)["{$url}"].Post
+		if op == nil || op.OperationID != opName {
+			t.Errorf("expected the callback for %s to describe the operation, got %v", opName, op)
+		}
+	}
+}
+
+func TestUnknownRPCStyle(t *testing.T) {
+	api := newTestAPI(t, "cognito-identity", cognitoIdentityModel)
+	opts := DefaultSchemaOptions()
+	opts.RPCStyle = "bogus"
+	if _, err := api.SchemaWithOptions(opts); err == nil {
+		t.Errorf("expected an error for RPC style %q", opts.RPCStyle)
+	}
+}
@@ -20,6 +20,14 @@ func newSwagger() *oai.Swagger {
 	return &swagger
 }
 
+// addExtension sets an OpenAPI extension ("x-...") property
+func addExtension(props *oai.ExtensionProps, key string, value interface{}) {
+	if props.Extensions == nil {
+		props.Extensions = map[string]interface{}{}
+	}
+	props.Extensions[key] = value
+}
+
 func newStringSchema(ss *shapeSpec) *oai.Schema {
 	schema := oai.NewStringSchema()
 	if ss.Min != nil {
@@ -78,6 +86,23 @@ func (api *API) newArraySchema(
 	if err != nil {
 		return nil, err
 	}
+	if api.apiSpec.Metadata.isXML() {
+		// List items are serialized inside a wrapping element as elements
+		// named "member" unless the list member says otherwise. The items of
+		// a flattened list are named after the structure member containing
+		// the list.
+		itemsXML := (&xmlObject{Name: listMemberShapeRef.LocationName}).withNamespace(listMemberShapeRef.XMLNamespace)
+		if !ss.Flattened {
+			if itemsXML.Name == "" {
+				itemsXML.Name = "member"
+			}
+			schema.XML = &xmlObject{Wrapped: true}
+		}
+		withXML(oai.NewSchemaRef("", itemsSchema), itemsXML)
+	}
+	if qs := api.apiSpec.Metadata.querySerialization(ss); qs != nil {
+		addExtension(&schema.ExtensionProps, "x-aws-query-serialization", qs)
+	}
 	schema.WithItems(itemsSchema)
 	if ss.Max != nil {
 		schema.WithMaxItems(int64(*ss.Max))
@@ -85,7 +110,43 @@ func (api *API) newArraySchema(
 	return schema, nil
 }
 
+// newMapSchema returns an object schema whose additionalProperties reference
+// the schema of the map's value shape. OpenAPI 3.0 cannot constrain property
+// names, so any constraints on the map's key shape are described in an
+// x-aws-map-key extension instead.
+func (api *API) newMapSchema(ss *shapeSpec) (*oai.Schema, error) {
+	if ss.MapKey == nil || ss.MapValue == nil {
+		return nil, fmt.Errorf("expected map key and value to be non-nil")
+	}
+	shapeMap := api.apiSpec.Shapes
+	valueShapeName := *ss.MapValue.ShapeName
+	if _, found := shapeMap[valueShapeName]; !found {
+		return nil, fmt.Errorf("expected to find map value shape %s", valueShapeName)
+	}
+	keyShapeName := *ss.MapKey.ShapeName
+	keyShape, found := shapeMap[keyShapeName]
+	if !found {
+		return nil, fmt.Errorf("expected to find map key shape %s", keyShapeName)
+	}
+	schema := oai.NewObjectSchema()
+	schema.AdditionalProperties = oai.NewSchemaRef("#/components/schemas/"+valueShapeName, nil)
+	if keyShape.Min != nil || keyShape.Max != nil || keyShape.Pattern != nil || len(keyShape.Enum) > 0 {
+		addExtension(&schema.ExtensionProps, "x-aws-map-key", newStringSchema(keyShape))
+	}
+	if qs := api.apiSpec.Metadata.querySerialization(ss); qs != nil {
+		addExtension(&schema.ExtensionProps, "x-aws-query-serialization", qs)
+	}
+	if ss.Min != nil {
+		schema.WithMinProperties(int64(*ss.Min))
+	}
+	if ss.Max != nil {
+		schema.WithMaxProperties(int64(*ss.Max))
+	}
+	return schema, nil
+}
+
 func (api *API) newObjectSchema(
+	shapeName string,
 	ss *shapeSpec,
 	visitedMemberShapeNames []string,
 ) (*oai.Schema, error) {
@@ -107,7 +168,7 @@ func (api *API) newObjectSchema(
 			_, refFound := shapeMap[refMemberShapeName]
 			if refFound {
 				refSchema := oai.NewSchemaRef("#/components/schemas/"+refMemberShapeName, nil)
-				schema.WithPropertyRef(memberName, refSchema)
+				schema.WithPropertyRef(memberName, api.wireMemberRef(memberName, memberShapeRef, memberShape, refSchema))
 				continue
 			}
 		}
@@ -115,21 +176,66 @@ func (api *API) newObjectSchema(
 		if err != nil {
 			return nil, err
 		}
-		schema.WithProperty(memberName, memberSchema)
+		memberSchemaRef := oai.NewSchemaRef("", memberSchema)
+		schema.WithPropertyRef(memberName, api.wireMemberRef(memberName, memberShapeRef, memberShape, memberSchemaRef))
 	}
 	if len(ss.Required) > 0 {
 		schema.Required = ss.Required
 	}
+	if api.apiSpec.Metadata.isXML() {
+		x := (&xmlObject{Name: ss.LocationName}).withNamespace(ss.XMLNamespace)
+		withXML(oai.NewSchemaRef("", schema), x)
+	}
 	// Helpfully decorate the object with an annotation if this object is an
 	// exception type
 	if ss.Exception {
-		exts := map[string]interface{}{}
-		schema.ExtensionProps = oai.ExtensionProps{exts}
-		schema.ExtensionProps.Extensions["x-aws-api-exception"] = true
+		addExtension(&schema.ExtensionProps, "x-aws-api-exception", true)
+		errorExtensions(schema, shapeName, ss)
 	}
 	return schema, nil
 }
 
+// wireMemberRef returns the schema of a member of a structure, adjusted for
+// how the member is serialized by XML and query protocol APIs. Members whose
+// query protocol form field name differs from the member name have an
+// x-aws-query-name extension.
+func (api *API) wireMemberRef(
+	memberName string,
+	ref *shapeRefSpec,
+	memberShape *shapeSpec,
+	schemaRef *oai.SchemaRef,
+) *oai.SchemaRef {
+	meta := &api.apiSpec.Metadata
+	if !meta.isXML() {
+		return schemaRef
+	}
+	x := (&xmlObject{Attribute: ref.XMLAttribute}).withNamespace(ref.XMLNamespace)
+	if ref.LocationName != "" && ref.LocationName != memberName {
+		x.Name = ref.LocationName
+	}
+	if ref.Flattened && memberShape.Type == "list" && !memberShape.Flattened {
+		// The list is only flattened when it is a member of this structure,
+		// so the wrapped list's component schema does not apply. Items of an
+		// unwrapped array are elements named after the member.
+		listMember := memberShape.ListMember
+		itemsRef := componentSchemaRef(*listMember.ShapeName)
+		if listMember.LocationName != "" {
+			itemsRef = withXML(itemsRef, &xmlObject{Name: listMember.LocationName})
+		}
+		schema := oai.NewArraySchema()
+		schema.Items = itemsRef
+		if meta.isQuery() {
+			addExtension(&schema.ExtensionProps, "x-aws-query-serialization", "{name}.{n}")
+		}
+		schemaRef = oai.NewSchemaRef("", schema)
+	}
+	schemaRef = withXML(schemaRef, x)
+	if queryName := meta.queryName(memberName, ref); meta.isQuery() && queryName != memberName {
+		schemaRef = withExtension(schemaRef, "x-aws-query-name", queryName)
+	}
+	return schemaRef
+}
+
 // given a shape name, return a new OpenAPI3 Schema representing the shape.
 func (api *API) newSchema(
 	shapeName string,
@@ -150,11 +256,11 @@ func (api *API) newSchema(
 	case "timestamp":
 		return oai.NewDateTimeSchema(), nil
 	case "map":
-		return oai.NewObjectSchema().WithAnyAdditionalProperties(), nil
+		return api.newMapSchema(ss)
 	case "list":
 		return api.newArraySchema(ss, visitedMemberShapeNames)
 	case "structure":
-		return api.newObjectSchema(ss, visitedMemberShapeNames)
+		return api.newObjectSchema(shapeName, ss, visitedMemberShapeNames)
 	}
 	return nil, fmt.Errorf("unknown shape type %s", ss.Type)
 }
@@ -0,0 +1,149 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+const tagsModel = `{
+  "metadata": {
+    "apiVersion": "2020-01-01",
+    "endpointPrefix": "tagging",
+    "jsonVersion": "1.1",
+    "protocol": "json",
+    "serviceFullName": "Tagging Service",
+    "serviceId": "Tagging",
+    "signatureVersion": "v4",
+    "targetPrefix": "Tagging"
+  },
+  "operations": {
+    "TagResource": {
+      "name": "TagResource",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "TagResourceRequest"}
+    }
+  },
+  "shapes": {
+    "TagResourceRequest": {
+      "type": "structure",
+      "members": {
+        "Tags": {"shape": "TagMap"},
+        "Attributes": {"shape": "AttributeMap"},
+        "Settings": {"shape": "SettingMap"}
+      }
+    },
+    "TagMap": {
+      "type": "map",
+      "key": {"shape": "TagKey"},
+      "value": {"shape": "TagValue"},
+      "min": 1,
+      "max": 50
+    },
+    "TagKey": {"type": "string", "min": 1, "max": 128, "pattern": "^[a-zA-Z0-9]+$"},
+    "TagValue": {"type": "string", "max": 256},
+    "AttributeMap": {
+      "type": "map",
+      "key": {"shape": "String"},
+      "value": {"shape": "String"}
+    },
+    "SettingMap": {
+      "type": "map",
+      "key": {"shape": "SettingName"},
+      "value": {"shape": "Setting"}
+    },
+    "SettingName": {"type": "string", "enum": ["Color", "Size"]},
+    "Setting": {
+      "type": "structure",
+      "members": {"Value": {"shape": "String"}}
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+func TestMapSchema(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "tagging", tagsModel))
+	tests := []struct {
+		shapeName     string
+		valueRef      string
+		minProperties uint64
+		maxProperties uint64
+		// keyConstraint is the constraint of the x-aws-map-key extension,
+		// or empty if there should be no extension
+		keyConstraint string
+	}{
+		{"TagMap", "#/components/schemas/TagValue", 1, 50, "pattern ^[a-zA-Z0-9]+$"},
+		// OpenAPI has nothing to say about unconstrained keys
+		{"AttributeMap", "#/components/schemas/String", 0, 0, ""},
+		{"SettingMap", "#/components/schemas/Setting", 0, 0, "enum Color,Size"},
+	}
+	for _, test := range tests {
+		schema := swagger.Components.Schemas[test.shapeName].Value
+		if schema.Type != "object" {
+			t.Errorf("%s: expected an object schema, got %s", test.shapeName, schema.Type)
+		}
+		if ref := schema.AdditionalProperties; ref == nil || ref.Ref != test.valueRef {
+			t.Errorf("%s: expected additionalProperties %s, got %v", test.shapeName, test.valueRef, ref)
+		}
+		if schema.MinProps != test.minProperties {
+			t.Errorf("%s: expected minProperties %d, got %d", test.shapeName, test.minProperties, schema.MinProps)
+		}
+		gotMax := uint64(0)
+		if schema.MaxProps != nil {
+			gotMax = *schema.MaxProps
+		}
+		if gotMax != test.maxProperties {
+			t.Errorf("%s: expected maxProperties %d, got %d", test.shapeName, test.maxProperties, gotMax)
+		}
+		ext, found := schema.Extensions["x-aws-map-key"]
+		if test.keyConstraint == "" {
+			if found {
+				t.Errorf("%s: expected no x-aws-map-key, got %v", test.shapeName, ext)
+			}
+			continue
+		}
+		key, ok := ext.(*oai.Schema)
+		if !ok {
+			t.Errorf("%s: expected an x-aws-map-key schema, got %v", test.shapeName, ext)
+			continue
+		}
+		enum := []string{}
+		for _, v := range key.Enum {
+			enum = append(enum, v.(string))
+		}
+		got := "pattern " + key.Pattern
+		if len(enum) > 0 {
+			got = "enum " + strings.Join(enum, ",")
+		}
+		if got != test.keyConstraint {
+			t.Errorf("%s: expected x-aws-map-key %s, got %s", test.shapeName, test.keyConstraint, got)
+		}
+	}
+	key := swagger.Components.Schemas["TagMap"].Value.Extensions["x-aws-map-key"].(*oai.Schema)
+	if key.MinLength != 1 || key.MaxLength == nil || *key.MaxLength != 128 {
+		t.Errorf("expected TagMap keys of 1 to 128 characters, got %d to %v", key.MinLength, key.MaxLength)
+	}
+}
+
+func TestMapObjects(t *testing.T) {
+	api := newTestAPI(t, "tagging", tagsModel)
+	got := []string{}
+	for _, obj := range api.GetObjects(&ObjectFilter{Types: []string{ObjectTypeMap}}) {
+		got = append(got, obj.Name)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "AttributeMap,SettingMap,TagMap" {
+		t.Errorf("expected the map objects AttributeMap, SettingMap and TagMap, got %v", got)
+	}
+	if maps := api.Summary().Maps; maps != 3 {
+		t.Errorf("expected a summary of 3 maps, got %d", maps)
+	}
+}
@@ -0,0 +1,175 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"strings"
+	"unicode"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// The authentication types of operations and signature versions of APIs
+const (
+	// AuthTypeNone is the authtype of operations that may be called
+	// anonymously
+	AuthTypeNone = "none"
+	// AuthTypeV4UnsignedBody is the authtype of operations whose requests are
+	// signed with SigV4 without signing the payload
+	AuthTypeV4UnsignedBody = "v4-unsigned-body"
+	// AuthTypeBearer is the authtype of operations, or the signature version
+	// of APIs, authenticated with a bearer token instead of a signature
+	AuthTypeBearer = "bearer"
+)
+
+// Names of the security schemes in the OpenAPI schema's components
+const (
+	securitySchemeSigV4  = "sigv4"
+	securitySchemeBearer = "bearer"
+)
+
+// signingName returns the service name used in the credential scope of
+// signed requests, which defaults to the endpoint prefix
+func (m *metadataSpec) signingName() string {
+	if m.SigningName != "" {
+		return m.SigningName
+	}
+	return m.EndpointPrefix
+}
+
+// sigV4SecurityScheme returns the security scheme for requests signed with an
+// AWS signature, described the way API Gateway imports it: as an API key in
+// the Authorization header with an x-amazon-apigateway-authtype of awsSigv4
+func (m *metadataSpec) sigV4SecurityScheme() *oai.SecurityScheme {
+	scheme := &oai.SecurityScheme{
+		Type:        "apiKey",
+		Name:        "Authorization",
+		In:          "header",
+		Description: "AWS Signature Version 4 signed request",
+	}
+	addExtension(&scheme.ExtensionProps, "x-amazon-apigateway-authtype", "awsSigv4")
+	if m.SignatureVersion != "" {
+		addExtension(&scheme.ExtensionProps, "x-aws-signature-version", m.SignatureVersion)
+	}
+	if name := m.signingName(); name != "" {
+		addExtension(&scheme.ExtensionProps, "x-aws-signing-name", name)
+	}
+	return scheme
+}
+
+// bearerSecurityScheme returns the security scheme for requests authenticated
+// with a bearer token
+func bearerSecurityScheme() *oai.SecurityScheme {
+	return &oai.SecurityScheme{
+		Type:   "http",
+		Scheme: "bearer",
+	}
+}
+
+// securityRequirement returns a security requirement for the named security
+// scheme
+func securityRequirement(schemeName string) oai.SecurityRequirements {
+	return oai.SecurityRequirements{
+		oai.NewSecurityRequirement().Authenticate(schemeName),
+	}
+}
+
+// addSecurity adds the security schemes used by the API's operations to the
+// OpenAPI schema's components, along with the security requirement that
+// applies to operations that do not override it
+func (m *metadataSpec) addSecurity(swagger *oai.Swagger, ops map[string]*opSpec) {
+	comps := &swagger.Components
+	comps.SecuritySchemes = map[string]*oai.SecuritySchemeRef{}
+	defaultScheme := securitySchemeSigV4
+	if m.SignatureVersion == AuthTypeBearer {
+		defaultScheme = securitySchemeBearer
+	}
+	schemes := map[string]bool{defaultScheme: true}
+	for _, opSpec := range ops {
+		switch opSpec.AuthType {
+		case AuthTypeBearer:
+			schemes[securitySchemeBearer] = true
+		case AuthTypeV4UnsignedBody:
+			schemes[securitySchemeSigV4] = true
+		}
+	}
+	if schemes[securitySchemeSigV4] {
+		comps.SecuritySchemes[securitySchemeSigV4] = &oai.SecuritySchemeRef{
+			Value: m.sigV4SecurityScheme(),
+		}
+	}
+	if schemes[securitySchemeBearer] {
+		comps.SecuritySchemes[securitySchemeBearer] = &oai.SecuritySchemeRef{
+			Value: bearerSecurityScheme(),
+		}
+	}
+	swagger.Security = securityRequirement(defaultScheme)
+}
+
+// applySecurity overrides the API's security requirement for an operation
+// whose authtype differs from the API's. Operations that may be called
+// anonymously have an empty security requirement and an x-aws-auth-type
+// extension records the operation's authtype.
+func (m *metadataSpec) applySecurity(op *oai.Operation, authType string) {
+	if authType == "" {
+		return
+	}
+	addExtension(&op.ExtensionProps, "x-aws-auth-type", authType)
+	switch authType {
+	case AuthTypeNone:
+		op.Security = oai.NewSecurityRequirements()
+	case AuthTypeBearer:
+		if m.SignatureVersion != AuthTypeBearer {
+			security := securityRequirement(securitySchemeBearer)
+			op.Security = &security
+		}
+	case AuthTypeV4UnsignedBody:
+		if m.SignatureVersion == AuthTypeBearer {
+			security := securityRequirement(securitySchemeSigV4)
+			op.Security = &security
+		}
+	}
+}
+
+// anonymousOperations contains the names, keyed by aws-sdk-go package name,
+// of the operations that may be called anonymously although their models do
+// not say so. The aws-sdk-go code generator backfills their authtype in the
+// same way.
+var anonymousOperations = map[string][]string{
+	"cognitoidentity": {
+		"GetId",
+		"GetOpenIdToken",
+		"UnlinkIdentity",
+		"GetCredentialsForIdentity",
+	},
+	"sts": {
+		"AssumeRoleWithSAML",
+		"AssumeRoleWithWebIdentity",
+	},
+}
+
+// packageName returns the aws-sdk-go package name of a service alias, which
+// is the alias lowercased without separators, e.g. "cognitoidentity" for both
+// "cognito-identity" and "Cognito Identity"
+func packageName(serviceAlias string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, serviceAlias)
+}
+
+// backfillAuthTypes sets the authtype of the service's anonymous operations
+// that do not already have one
+func backfillAuthTypes(serviceAlias string, spec *apiSpec) {
+	for _, opName := range anonymousOperations[packageName(serviceAlias)] {
+		if opSpec, found := spec.Operations[opName]; found && opSpec.AuthType == "" {
+			opSpec.AuthType = AuthTypeNone
+		}
+	}
+}
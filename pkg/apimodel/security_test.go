@@ -0,0 +1,85 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"testing"
+)
+
+const cognitoIdentityModel = `{
+  "metadata": {
+    "apiVersion": "2014-06-30",
+    "endpointPrefix": "cognito-identity",
+    "jsonVersion": "1.1",
+    "protocol": "json",
+    "serviceFullName": "Amazon Cognito Identity",
+    "serviceId": "Cognito Identity",
+    "signatureVersion": "v4",
+    "targetPrefix": "AWSCognitoIdentityService"
+  },
+  "operations": {
+    "GetId": {
+      "name": "GetId",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "GetIdInput"},
+      "output": {"shape": "GetIdResponse"}
+    },
+    "DescribeIdentity": {
+      "name": "DescribeIdentity",
+      "http": {"method": "POST", "requestUri": "/"},
+      "input": {"shape": "DescribeIdentityInput"},
+      "output": {"shape": "GetIdResponse"}
+    }
+  },
+  "shapes": {
+    "GetIdInput": {
+      "type": "structure",
+      "required": ["IdentityPoolId"],
+      "members": {"IdentityPoolId": {"shape": "String"}}
+    },
+    "DescribeIdentityInput": {
+      "type": "structure",
+      "required": ["IdentityId"],
+      "members": {"IdentityId": {"shape": "String"}}
+    },
+    "GetIdResponse": {
+      "type": "structure",
+      "members": {"IdentityId": {"shape": "String"}}
+    },
+    "String": {"type": "string"}
+  }
+}`
+
+func TestAnonymousOperations(t *testing.T) {
+	tests := []struct {
+		serviceAlias string
+		operationID  string
+		anonymous    bool
+	}{
+		{"cognito-identity", "GetId", true},
+		{"cognito-identity", "DescribeIdentity", false},
+		// The operations are looked up by package name, not by the models
+		// directory, so another spelling of the alias works too
+		{"cognitoidentity", "GetId", true},
+	}
+	for _, test := range tests {
+		swagger := testSchema(t, newTestAPI(t, test.serviceAlias, cognitoIdentityModel))
+		_, op := findOperation(t, swagger, test.operationID)
+		if !test.anonymous {
+			if op.Security != nil {
+				t.Errorf("%s %s: expected API-wide security, got %v", test.serviceAlias, test.operationID, *op.Security)
+			}
+			continue
+		}
+		if op.Security == nil || len(*op.Security) != 0 {
+			t.Errorf("%s %s: expected security: [], got %v", test.serviceAlias, test.operationID, op.Security)
+		}
+		if authType := op.Extensions["x-aws-auth-type"]; authType != AuthTypeNone {
+			t.Errorf("%s %s: expected x-aws-auth-type %q, got %v", test.serviceAlias, test.operationID, AuthTypeNone, authType)
+		}
+	}
+}
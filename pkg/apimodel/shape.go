@@ -0,0 +1,191 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"sort"
+)
+
+// XMLNamespace is the XML namespace a shape or member is serialized in for
+// the XML protocols
+type XMLNamespace struct {
+	Prefix string
+	URI    string
+}
+
+// ShapeRef describes a reference to a shape, either from a member of a
+// structure, list or map shape or from an operation's input, output or
+// errors
+type ShapeRef struct {
+	// ShapeName is the name of the referenced shape
+	ShapeName string
+	// Location is where the member is serialized in the HTTP request or
+	// response: "uri", "querystring", "header", "headers" or "statusCode".
+	// Empty means the member is serialized in the body.
+	Location string
+	// LocationName is the name of the member on the wire, e.g. the header or
+	// query string parameter name, if different from the member name
+	LocationName string
+	// QueryName is the name of the member in EC2 query requests
+	QueryName         string
+	XMLNamespace      *XMLNamespace
+	XMLAttribute      bool
+	Flattened         bool
+	Deprecated        bool
+	DeprecatedMessage string
+	// IdempotencyToken is true if the member is automatically filled with a
+	// unique value when not supplied
+	IdempotencyToken bool
+	Streaming        bool
+	// JSONValue is true if the member is a string containing JSON
+	JSONValue bool
+	// HostLabel is true if the member's value is inserted into the endpoint
+	// host prefix of the operation
+	HostLabel       bool
+	Box             bool
+	TimestampFormat string
+	// ResultWrapper is the name of the XML element wrapping the members of
+	// an operation's output in query protocol responses
+	ResultWrapper string
+}
+
+// Shape describes one of the shapes (data types) in an API model
+type Shape struct {
+	Name string
+	// Type is the shape type, e.g. "structure", "list", "map", "string"
+	Type     string
+	Required []string
+	// Members are the members of a structure shape, keyed by member name
+	Members map[string]*ShapeRef
+	// Member is the member of a list shape
+	Member *ShapeRef
+	// Key and Value are the key and value of a map shape
+	Key     *ShapeRef
+	Value   *ShapeRef
+	Min     *float64
+	Max     *float64
+	Pattern string
+	Enum    []interface{}
+	// Payload is the name of the member of an operation's input or output
+	// shape that is serialized as the entire HTTP body
+	Payload           string
+	Exception         bool
+	LocationName      string
+	XMLNamespace      *XMLNamespace
+	Flattened         bool
+	Deprecated        bool
+	DeprecatedMessage string
+	Sensitive         bool
+	Streaming         bool
+	// EventStream is true if the shape is a stream of the events in Members
+	EventStream bool
+	// Event is true if the shape is one of the events of an event stream
+	Event bool
+	// Document is true if the shape is an untyped (JSON) document
+	Document bool
+	// Union is true if exactly one of the shape's members may be set
+	Union           bool
+	Box             bool
+	TimestampFormat string
+}
+
+func newXMLNamespace(spec *xmlNamespaceSpec) *XMLNamespace {
+	if spec == nil {
+		return nil
+	}
+	return &XMLNamespace{Prefix: spec.Prefix, URI: spec.URI}
+}
+
+func newShapeRef(spec *shapeRefSpec) *ShapeRef {
+	if spec == nil {
+		return nil
+	}
+	ref := &ShapeRef{
+		LocationName:      spec.LocationName,
+		QueryName:         spec.QueryName,
+		XMLNamespace:      newXMLNamespace(spec.XMLNamespace),
+		XMLAttribute:      spec.XMLAttribute,
+		Flattened:         spec.Flattened,
+		Deprecated:        spec.Deprecated,
+		DeprecatedMessage: spec.DeprecatedMessage,
+		IdempotencyToken:  spec.IdempotencyToken,
+		Streaming:         spec.Streaming,
+		JSONValue:         spec.JSONValue,
+		HostLabel:         spec.HostLabel,
+		Box:               spec.Box,
+		TimestampFormat:   spec.TimestampFormat,
+		ResultWrapper:     spec.ResultWrapper,
+	}
+	if spec.ShapeName != nil {
+		ref.ShapeName = *spec.ShapeName
+	}
+	if spec.Location != nil {
+		ref.Location = *spec.Location
+	}
+	return ref
+}
+
+func newShape(name string, spec *shapeSpec) *Shape {
+	shape := &Shape{
+		Name:              name,
+		Type:              spec.Type,
+		Required:          spec.Required,
+		Member:            newShapeRef(spec.ListMember),
+		Key:               newShapeRef(spec.MapKey),
+		Value:             newShapeRef(spec.MapValue),
+		Min:               spec.Min,
+		Max:               spec.Max,
+		Enum:              spec.Enum,
+		Payload:           spec.Payload,
+		Exception:         spec.Exception,
+		LocationName:      spec.LocationName,
+		XMLNamespace:      newXMLNamespace(spec.XMLNamespace),
+		Flattened:         spec.Flattened,
+		Deprecated:        spec.Deprecated,
+		DeprecatedMessage: spec.DeprecatedMessage,
+		Sensitive:         spec.Sensitive,
+		Streaming:         spec.Streaming,
+		EventStream:       spec.EventStream,
+		Event:             spec.Event,
+		Document:          spec.Document,
+		Union:             spec.Union,
+		Box:               spec.Box,
+		TimestampFormat:   spec.TimestampFormat,
+	}
+	if spec.Pattern != nil {
+		shape.Pattern = *spec.Pattern
+	}
+	if len(spec.Members) > 0 {
+		shape.Members = make(map[string]*ShapeRef, len(spec.Members))
+		for memberName, memberSpec := range spec.Members {
+			shape.Members[memberName] = newShapeRef(memberSpec)
+		}
+	}
+	return shape
+}
+
+// GetShape returns the shape with the supplied name or nil if the API has no
+// such shape
+func (a *API) GetShape(shapeName string) *Shape {
+	spec, found := a.apiSpec.Shapes[shapeName]
+	if !found {
+		return nil
+	}
+	return newShape(shapeName, spec)
+}
+
+// GetShapes returns all of the API's shapes sorted by shape name
+func (a *API) GetShapes() []*Shape {
+	res := make([]*Shape, 0, len(a.apiSpec.Shapes))
+	for shapeName, spec := range a.apiSpec.Shapes {
+		res = append(res, newShape(shapeName, spec))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,979 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Smithy JSON AST models (as published in the aws-sdk-go-v2
+// codegen/sdk-codegen/aws-models/ directory) describe the same APIs as the
+// CORAL api-2.json/docs-2.json pair, but as a flat map of namespaced shape IDs
+// (e.g. "com.amazonaws.sqs#CreateQueue") to shapes, with everything else
+// expressed as traits on those shapes. We translate a Smithy model into the
+// same apiSpec/docSpec structures parsed from CORAL models so that the rest
+// of the package does not need to care which kind of model it was given.
+//
+// See https://awslabs.github.io/smithy/1.0/spec/core/json-ast.html
+
+const (
+	smithyPreludeNamespace = "smithy.api"
+	smithyUnitShapeID      = "smithy.api#Unit"
+)
+
+type smithyRef struct {
+	Target string `json:"target"`
+}
+
+type smithyMember struct {
+	Target string                     `json:"target"`
+	Traits map[string]json.RawMessage `json:"traits"`
+}
+
+type smithyShape struct {
+	Type    string                     `json:"type"`
+	Traits  map[string]json.RawMessage `json:"traits"`
+	Members map[string]*smithyMember   `json:"members"`
+	// list and set shapes
+	Member *smithyMember `json:"member"`
+	// map shapes
+	Key   *smithyMember `json:"key"`
+	Value *smithyMember `json:"value"`
+	// operation shapes
+	Input  *smithyRef   `json:"input"`
+	Output *smithyRef   `json:"output"`
+	Errors []*smithyRef `json:"errors"`
+	// service and resource shapes
+	Version              string       `json:"version"`
+	Operations           []*smithyRef `json:"operations"`
+	CollectionOperations []*smithyRef `json:"collectionOperations"`
+	Resources            []*smithyRef `json:"resources"`
+	Create               *smithyRef   `json:"create"`
+	Put                  *smithyRef   `json:"put"`
+	Read                 *smithyRef   `json:"read"`
+	Update               *smithyRef   `json:"update"`
+	Delete               *smithyRef   `json:"delete"`
+	List                 *smithyRef   `json:"list"`
+}
+
+type smithyModel struct {
+	Smithy string                  `json:"smithy"`
+	Shapes map[string]*smithyShape `json:"shapes"`
+}
+
+// smithyProtocols maps the Smithy AWS protocol traits to the protocol names
+// used in CORAL models
+var smithyProtocols = map[string]string{
+	"aws.protocols#awsJson1_0": "json",
+	"aws.protocols#awsJson1_1": "json",
+	"aws.protocols#restJson1":  "rest-json",
+	"aws.protocols#restXml":    "rest-xml",
+	"aws.protocols#awsQuery":   "query",
+	"aws.protocols#ec2Query":   "ec2",
+}
+
+// smithyJSONVersions maps the Smithy AWS JSON protocol traits to the JSON
+// versions used in CORAL models
+var smithyJSONVersions = map[string]string{
+	"aws.protocols#awsJson1_0": "1.0",
+	"aws.protocols#awsJson1_1": "1.1",
+}
+
+// smithySimpleTypes maps Smithy simple shape types to CORAL shape types
+var smithySimpleTypes = map[string]string{
+	"blob":       "blob",
+	"boolean":    "boolean",
+	"string":     "string",
+	"enum":       "string",
+	"byte":       "integer",
+	"short":      "integer",
+	"integer":    "integer",
+	"intEnum":    "integer",
+	"long":       "long",
+	"float":      "float",
+	"double":     "double",
+	"bigInteger": "long",
+	"bigDecimal": "double",
+	"timestamp":  "timestamp",
+}
+
+// isSmithyModel returns true if the supplied model JSON is a Smithy JSON AST
+// document rather than a CORAL api-2.json document
+func isSmithyModel(modelBytes []byte) bool {
+	var probe struct {
+		Smithy *string `json:"smithy"`
+	}
+	if err := json.Unmarshal(modelBytes, &probe); err != nil {
+		return false
+	}
+	return probe.Smithy != nil
+}
+
+// smithyConverter holds the state needed to translate a Smithy model into an
+// apiSpec and docSpec
+type smithyConverter struct {
+	model   *smithyModel
+	api     *apiSpec
+	docs    *docSpec
+	service *smithyShape
+	// shapeNames maps Smithy shape IDs to the (un-namespaced) shape names
+	// used in the apiSpec
+	shapeNames map[string]string
+}
+
+// parseSmithy returns an apiSpec and docSpec describing the service in the
+// supplied Smithy JSON AST model
+func parseSmithy(modelBytes []byte) (*apiSpec, *docSpec, error) {
+	var model smithyModel
+	if err := json.Unmarshal(modelBytes, &model); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode Smithy model: %v", err)
+	}
+	c := &smithyConverter{
+		model: &model,
+		api: &apiSpec{
+			Operations: map[string]*opSpec{},
+			Shapes:     map[string]*shapeSpec{},
+			Paginators: map[string]*paginatorSpec{},
+			Waiters:    map[string]*waiterSpec{},
+			Examples:   map[string][]*exampleSpec{},
+		},
+		docs: &docSpec{
+			Operations: map[string]string{},
+			Shapes:     map[string]*shapeDocSpec{},
+		},
+		shapeNames: map[string]string{},
+	}
+	if err := c.convert(); err != nil {
+		return nil, nil, err
+	}
+	return c.api, c.docs, nil
+}
+
+func (c *smithyConverter) convert() error {
+	serviceID := ""
+	ids := make([]string, 0, len(c.model.Shapes))
+	for id := range c.model.Shapes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if c.model.Shapes[id].Type == "service" {
+			serviceID = id
+			break
+		}
+	}
+	if serviceID == "" {
+		return fmt.Errorf("expected to find a service shape in Smithy model")
+	}
+	c.service = c.model.Shapes[serviceID]
+	c.convertMetadata(serviceID)
+
+	// Name every non-prelude shape first so that prelude shapes that collide
+	// with a model shape's name can be renamed
+	c.nameShapes(ids, namespaceOf(serviceID))
+
+	opIDs, err := c.serviceOperations(serviceID)
+	if err != nil {
+		return err
+	}
+	for _, opID := range opIDs {
+		if err := c.convertOperation(opID); err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		shape := c.model.Shapes[id]
+		switch shape.Type {
+		case "service", "operation", "resource":
+			continue
+		}
+		if _, err := c.convertShape(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *smithyConverter) convertMetadata(serviceID string) {
+	meta := &c.api.Metadata
+	meta.APIVersion = c.service.Version
+	traitString(c.service.Traits, "smithy.api#title", &meta.FullName)
+	var awsService struct {
+		SdkID          string `json:"sdkId"`
+		EndpointPrefix string `json:"endpointPrefix"`
+	}
+	traitValue(c.service.Traits, "aws.api#service", &awsService)
+	meta.Alias = awsService.SdkID
+	meta.EndpointPrefix = awsService.EndpointPrefix
+	for trait, protocol := range smithyProtocols {
+		if _, found := c.service.Traits[trait]; found {
+			meta.Protocol = protocol
+			meta.JSONVersion = smithyJSONVersions[trait]
+			if meta.JSONVersion != "" {
+				// The AWS JSON protocols target operations by the name of
+				// the service shape
+				meta.TargetPrefix = shapeNameOf(serviceID)
+			}
+			break
+		}
+	}
+	var ns xmlNamespaceSpec
+	if traitValue(c.service.Traits, "smithy.api#xmlNamespace", &ns) {
+		meta.XMLNamespace = ns.URI
+	}
+	var sigV4 struct {
+		Name string `json:"name"`
+	}
+	if traitValue(c.service.Traits, "aws.auth#sigv4", &sigV4) {
+		meta.SignatureVersion = "v4"
+		meta.SigningName = sigV4.Name
+	} else if hasTrait(c.service.Traits, "smithy.api#httpBearerAuth") {
+		meta.SignatureVersion = AuthTypeBearer
+	}
+	traitString(c.service.Traits, "smithy.api#documentation", &c.docs.Service)
+}
+
+// serviceOperations returns the IDs of all operations bound to the service,
+// either directly or via the service's (possibly nested) resources
+func (c *smithyConverter) serviceOperations(serviceID string) ([]string, error) {
+	seen := map[string]bool{}
+	res := []string{}
+	var visit func(id string) error
+	visit = func(id string) error {
+		shape, found := c.model.Shapes[id]
+		if !found {
+			return fmt.Errorf("expected to find Smithy shape %s", id)
+		}
+		refs := []*smithyRef{
+			shape.Create, shape.Put, shape.Read,
+			shape.Update, shape.Delete, shape.List,
+		}
+		refs = append(refs, shape.Operations...)
+		refs = append(refs, shape.CollectionOperations...)
+		for _, ref := range refs {
+			if ref == nil || seen[ref.Target] {
+				continue
+			}
+			seen[ref.Target] = true
+			res = append(res, ref.Target)
+		}
+		for _, ref := range shape.Resources {
+			if err := visit(ref.Target); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(serviceID); err != nil {
+		return nil, err
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (c *smithyConverter) convertOperation(opID string) error {
+	shape, found := c.model.Shapes[opID]
+	if !found || shape.Type != "operation" {
+		return fmt.Errorf("expected to find Smithy operation shape %s", opID)
+	}
+	opName := c.shapeNames[opID]
+	op := &opSpec{
+		HTTP: &httpSpec{Method: "POST"},
+	}
+	requestURI := "/"
+	var http struct {
+		Method string `json:"method"`
+		URI    string `json:"uri"`
+		Code   *int   `json:"code"`
+	}
+	if traitValue(shape.Traits, "smithy.api#http", &http) {
+		op.HTTP.Method = http.Method
+		requestURI = http.URI
+		op.HTTP.ResponseCode = http.Code
+	}
+	op.HTTP.RequestURI = &requestURI
+	var err error
+	if op.Input, err = c.convertOpRef(shape.Input); err != nil {
+		return err
+	}
+	if op.Output, err = c.convertOpRef(shape.Output); err != nil {
+		return err
+	}
+	for _, errRef := range shape.Errors {
+		ref, err := c.convertOpRef(errRef)
+		if err != nil {
+			return err
+		}
+		op.Errors = append(op.Errors, ref)
+	}
+	c.convertOperationTraits(shape.Traits, op)
+	c.api.Operations[opName] = op
+	c.convertPaginated(opName, shape.Traits)
+	c.convertWaitable(opName, shape.Traits)
+	c.convertExamples(opName, shape.Traits)
+	var doc string
+	if traitString(shape.Traits, "smithy.api#documentation", &doc) {
+		c.docs.Operations[opName] = doc
+	}
+	return nil
+}
+
+// convertOperationTraits copies the Smithy traits of an operation shape that
+// have an equivalent in CORAL models to the opSpec
+func (c *smithyConverter) convertOperationTraits(
+	traits map[string]json.RawMessage,
+	op *opSpec,
+) {
+	var auth []string
+	if hasTrait(traits, "smithy.api#optionalAuth") ||
+		(traitValue(traits, "smithy.api#auth", &auth) && len(auth) == 0) {
+		op.AuthType = AuthTypeNone
+	} else if hasTrait(traits, "aws.auth#unsignedPayload") {
+		op.AuthType = AuthTypeV4UnsignedBody
+	} else if len(auth) == 1 && auth[0] == "smithy.api#httpBearerAuth" {
+		op.AuthType = AuthTypeBearer
+	}
+	var endpoint endpointSpec
+	if traitValue(traits, "smithy.api#endpoint", &endpoint) {
+		op.Endpoint = &endpoint
+	}
+	op.HTTPChecksumRequired = hasTrait(traits, "smithy.api#httpChecksumRequired")
+	op.Deprecated, op.DeprecatedMessage = deprecation(traits)
+	// CORAL models only have a single documentation URL, so prefer the API
+	// reference over any other external documentation links
+	var docLinks map[string]string
+	if traitValue(traits, "smithy.api#externalDocumentation", &docLinks) {
+		if url, found := docLinks["API Reference"]; found {
+			op.DocumentationURL = url
+		} else {
+			titles := make([]string, 0, len(docLinks))
+			for title := range docLinks {
+				titles = append(titles, title)
+			}
+			sort.Strings(titles)
+			if len(titles) > 0 {
+				op.DocumentationURL = docLinks[titles[0]]
+			}
+		}
+	}
+}
+
+// smithyPaginated is the value of the smithy.api#paginated trait
+type smithyPaginated struct {
+	InputToken  string `json:"inputToken"`
+	OutputToken string `json:"outputToken"`
+	Items       string `json:"items"`
+	PageSize    string `json:"pageSize"`
+}
+
+// convertPaginated adds a paginatorSpec for an operation with the
+// smithy.api#paginated trait. Any member not set on the operation's trait is
+// inherited from the service's smithy.api#paginated trait.
+func (c *smithyConverter) convertPaginated(
+	opName string,
+	traits map[string]json.RawMessage,
+) {
+	var pag smithyPaginated
+	if !traitValue(traits, "smithy.api#paginated", &pag) {
+		return
+	}
+	var defaults smithyPaginated
+	traitValue(c.service.Traits, "smithy.api#paginated", &defaults)
+	if pag.InputToken == "" {
+		pag.InputToken = defaults.InputToken
+	}
+	if pag.OutputToken == "" {
+		pag.OutputToken = defaults.OutputToken
+	}
+	if pag.Items == "" {
+		pag.Items = defaults.Items
+	}
+	if pag.PageSize == "" {
+		pag.PageSize = defaults.PageSize
+	}
+	spec := &paginatorSpec{LimitKey: pag.PageSize}
+	if pag.InputToken != "" {
+		spec.InputToken = stringOrList{pag.InputToken}
+	}
+	if pag.OutputToken != "" {
+		spec.OutputToken = stringOrList{pag.OutputToken}
+	}
+	if pag.Items != "" {
+		spec.ResultKey = stringOrList{pag.Items}
+	}
+	c.api.Paginators[opName] = spec
+}
+
+// smithyWaiter is a waiter in the value of the smithy.waiters#waitable trait
+type smithyWaiter struct {
+	MinDelay  int                     `json:"minDelay"`
+	Acceptors []*smithyWaiterAcceptor `json:"acceptors"`
+}
+
+type smithyWaiterAcceptor struct {
+	State   string `json:"state"`
+	Matcher struct {
+		Output      *smithyPathMatcher `json:"output"`
+		InputOutput *smithyPathMatcher `json:"inputOutput"`
+		Success     *bool              `json:"success"`
+		ErrorType   string             `json:"errorType"`
+	} `json:"matcher"`
+}
+
+type smithyPathMatcher struct {
+	Path       string `json:"path"`
+	Expected   string `json:"expected"`
+	Comparator string `json:"comparator"`
+}
+
+// smithyWaiterDefaultMinDelay is the minDelay of a Smithy waiter that does
+// not specify one
+const smithyWaiterDefaultMinDelay = 2
+
+// smithyComparatorMatchers maps Smithy waiter path comparators to the
+// equivalent waiters-2.json matcher
+var smithyComparatorMatchers = map[string]string{
+	"stringEquals":    "path",
+	"booleanEquals":   "path",
+	"allStringEquals": "pathAll",
+	"anyStringEquals": "pathAny",
+}
+
+// convertWaitable adds a waiterSpec for each waiter in an operation's
+// smithy.waiters#waitable trait. Smithy waiters have no maximum number of
+// attempts, so MaxAttempts is left at zero.
+func (c *smithyConverter) convertWaitable(
+	opName string,
+	traits map[string]json.RawMessage,
+) {
+	var waiters map[string]*smithyWaiter
+	if !traitValue(traits, "smithy.waiters#waitable", &waiters) {
+		return
+	}
+	for name, waiter := range waiters {
+		spec := &waiterSpec{
+			Operation: opName,
+			Delay:     waiter.MinDelay,
+		}
+		if spec.Delay == 0 {
+			spec.Delay = smithyWaiterDefaultMinDelay
+		}
+		for _, acc := range waiter.Acceptors {
+			accSpec := &waiterAcceptorSpec{State: acc.State}
+			m := acc.Matcher
+			path := m.Output
+			if path == nil {
+				path = m.InputOutput
+			}
+			switch {
+			case path != nil:
+				accSpec.Matcher = smithyComparatorMatchers[path.Comparator]
+				accSpec.Argument = path.Path
+				accSpec.Expected = path.Expected
+				if path.Comparator == "booleanEquals" {
+					accSpec.Expected = path.Expected == "true"
+				}
+			case m.Success != nil && *m.Success:
+				accSpec.Matcher = "status"
+				accSpec.Expected = 200
+			case m.Success != nil:
+				accSpec.Matcher = "error"
+				accSpec.Expected = true
+			case m.ErrorType != "":
+				accSpec.Matcher = "error"
+				accSpec.Expected = m.ErrorType
+			default:
+				continue
+			}
+			spec.Acceptors = append(spec.Acceptors, accSpec)
+		}
+		c.api.Waiters[name] = spec
+	}
+}
+
+// smithyExample is an example in the value of the smithy.api#examples trait
+type smithyExample struct {
+	Title         string      `json:"title"`
+	Documentation string      `json:"documentation"`
+	Input         interface{} `json:"input"`
+	Output        interface{} `json:"output"`
+}
+
+// convertExamples adds an exampleSpec for each example in an operation's
+// smithy.api#examples trait
+func (c *smithyConverter) convertExamples(
+	opName string,
+	traits map[string]json.RawMessage,
+) {
+	var examples []*smithyExample
+	if !traitValue(traits, "smithy.api#examples", &examples) {
+		return
+	}
+	for _, example := range examples {
+		c.api.Examples[opName] = append(c.api.Examples[opName], &exampleSpec{
+			Title:       example.Title,
+			Description: example.Documentation,
+			Input:       example.Input,
+			Output:      example.Output,
+		})
+	}
+}
+
+// convertOpRef returns a shapeRefSpec pointing at an operation's input,
+// output or error shape, or nil if the operation has no such shape
+func (c *smithyConverter) convertOpRef(ref *smithyRef) (*shapeRefSpec, error) {
+	if ref == nil || ref.Target == smithyUnitShapeID {
+		return nil, nil
+	}
+	name, err := c.convertShape(ref.Target)
+	if err != nil {
+		return nil, err
+	}
+	return &shapeRefSpec{ShapeName: &name}, nil
+}
+
+// convertShape adds the shape with the supplied Smithy shape ID to the apiSpec
+// (if it has not been added already) and returns its shape name
+func (c *smithyConverter) convertShape(id string) (string, error) {
+	name, named := c.shapeNames[id]
+	if named {
+		if _, done := c.api.Shapes[name]; done {
+			return name, nil
+		}
+	}
+	shape, found := c.model.Shapes[id]
+	if !found {
+		if namespaceOf(id) != smithyPreludeNamespace {
+			return "", fmt.Errorf("expected to find Smithy shape %s", id)
+		}
+		return c.convertPreludeShape(id)
+	}
+	ss := &shapeSpec{}
+	// Add the shape before converting members so that recursive shapes
+	// terminate
+	c.api.Shapes[name] = ss
+	if err := c.fillShapeSpec(name, shape, ss); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// nameShapes names the supplied non-prelude shapes. CORAL shape names have no
+// namespace, so when shapes in different namespaces have the same name, the
+// shape in the service's namespace keeps the name and the others are
+// prefixed with their namespace, e.g. "ComAmazonawsCommonError" for
+// "com.amazonaws.common#Error".
+func (c *smithyConverter) nameShapes(ids []string, serviceNamespace string) {
+	byName := map[string][]string{}
+	for _, id := range ids {
+		if namespaceOf(id) == smithyPreludeNamespace {
+			continue
+		}
+		name := shapeNameOf(id)
+		byName[name] = append(byName[name], id)
+	}
+	for name, named := range byName {
+		if len(named) == 1 {
+			c.shapeNames[named[0]] = name
+			continue
+		}
+		for _, id := range named {
+			if namespaceOf(id) == serviceNamespace {
+				c.shapeNames[id] = name
+			} else {
+				c.shapeNames[id] = qualifiedShapeName(id)
+			}
+		}
+	}
+}
+
+// qualifiedShapeName returns the shape name of a Smithy shape ID prefixed
+// with its CamelCased namespace, e.g. "ComAmazonawsCommonError" for
+// "com.amazonaws.common#Error"
+func qualifiedShapeName(id string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(namespaceOf(id), ".") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	b.WriteString(shapeNameOf(id))
+	return b.String()
+}
+
+// convertPreludeShape adds a shape for one of the Smithy prelude shapes
+// (smithy.api#String, smithy.api#Integer, etc) and returns its shape name.
+// CORAL models have no prelude, so the prelude shapes need to be added as
+// regular shapes, prefixed with "Smithy" if the model has its own shape of
+// the same name.
+func (c *smithyConverter) convertPreludeShape(id string) (string, error) {
+	name := shapeNameOf(id)
+	// e.g. smithy.api#PrimitiveInteger -> integer
+	typ := strings.ToLower(strings.TrimPrefix(name, "Primitive"))
+	for simple, coral := range smithySimpleTypes {
+		if strings.ToLower(simple) == typ {
+			typ = coral
+		}
+	}
+	if typ == "document" || typ == "unit" {
+		typ = "structure"
+	} else if !inStrings(typ, []string{
+		"blob", "boolean", "string", "integer", "long", "float", "double", "timestamp",
+	}) {
+		return "", fmt.Errorf("unsupported Smithy prelude shape %s", id)
+	}
+	if c.isShapeNameTaken(name) {
+		name = "Smithy" + name
+	}
+	c.shapeNames[id] = name
+	c.api.Shapes[name] = &shapeSpec{Type: typ}
+	return name, nil
+}
+
+// isShapeNameTaken returns true if a model shape that has not yet been
+// converted will use the supplied shape name
+func (c *smithyConverter) isShapeNameTaken(name string) bool {
+	for id, n := range c.shapeNames {
+		if n == name && namespaceOf(id) != smithyPreludeNamespace {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *smithyConverter) fillShapeSpec(
+	name string,
+	shape *smithyShape,
+	ss *shapeSpec,
+) error {
+	switch shape.Type {
+	case "structure", "union", "document":
+		ss.Type = "structure"
+	case "list", "set":
+		ss.Type = "list"
+	case "map":
+		ss.Type = "map"
+	default:
+		typ, found := smithySimpleTypes[shape.Type]
+		if !found {
+			return fmt.Errorf("unsupported Smithy shape type %s for %s", shape.Type, name)
+		}
+		ss.Type = typ
+	}
+	var doc string
+	if traitString(shape.Traits, "smithy.api#documentation", &doc) {
+		c.shapeDoc(name).Base = &doc
+	}
+	c.convertShapeTraits(shape, ss)
+	c.convertConstraints(shape.Traits, ss)
+	c.convertError(name, shape.Traits, ss)
+
+	switch shape.Type {
+	case "enum", "intEnum":
+		// Smithy 2.0 enums are shapes whose members are the enum values
+		memberNames := make([]string, 0, len(shape.Members))
+		for memberName := range shape.Members {
+			memberNames = append(memberNames, memberName)
+		}
+		sort.Strings(memberNames)
+		for _, memberName := range memberNames {
+			var value interface{} = memberName
+			traitValue(shape.Members[memberName].Traits, "smithy.api#enumValue", &value)
+			ss.Enum = append(ss.Enum, value)
+		}
+		return nil
+	case "list", "set":
+		if shape.Member == nil {
+			return fmt.Errorf("expected list member for Smithy shape %s", name)
+		}
+		ref, err := c.convertMember(name, "member", shape.Member)
+		if err != nil {
+			return err
+		}
+		ss.ListMember = ref
+		return nil
+	case "map":
+		if shape.Key == nil || shape.Value == nil {
+			return fmt.Errorf("expected map key and value for Smithy shape %s", name)
+		}
+		var err error
+		if ss.MapKey, err = c.convertMember(name, "key", shape.Key); err != nil {
+			return err
+		}
+		if ss.MapValue, err = c.convertMember(name, "value", shape.Value); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if len(shape.Members) > 0 {
+		ss.Members = map[string]*shapeRefSpec{}
+	}
+	memberNames := make([]string, 0, len(shape.Members))
+	for memberName := range shape.Members {
+		memberNames = append(memberNames, memberName)
+	}
+	sort.Strings(memberNames)
+	for _, memberName := range memberNames {
+		member := shape.Members[memberName]
+		ref, err := c.convertMember(name, memberName, member)
+		if err != nil {
+			return err
+		}
+		ss.Members[memberName] = ref
+		if _, required := member.Traits["smithy.api#required"]; required {
+			ss.Required = append(ss.Required, memberName)
+		}
+		if hasTrait(member.Traits, "smithy.api#httpPayload") {
+			ss.Payload = memberName
+		}
+		if ss.EventStream {
+			c.api.Shapes[*ref.ShapeName].Event = true
+		}
+	}
+	return nil
+}
+
+// convertShapeTraits copies the Smithy traits of a shape that have an
+// equivalent in CORAL models to the shapeSpec
+func (c *smithyConverter) convertShapeTraits(
+	shape *smithyShape,
+	ss *shapeSpec,
+) {
+	traits := shape.Traits
+	traitString(traits, "smithy.api#xmlName", &ss.LocationName)
+	var ns xmlNamespaceSpec
+	if traitValue(traits, "smithy.api#xmlNamespace", &ns) {
+		ss.XMLNamespace = &ns
+	}
+	ss.Deprecated, ss.DeprecatedMessage = deprecation(traits)
+	ss.Sensitive = hasTrait(traits, "smithy.api#sensitive")
+	ss.Box = hasTrait(traits, "smithy.api#box")
+	traitString(traits, "smithy.api#timestampFormat", &ss.TimestampFormat)
+	switch shape.Type {
+	case "blob":
+		ss.Streaming = hasTrait(traits, "smithy.api#streaming")
+	case "union":
+		ss.Union = true
+		// A union with the streaming trait is an event stream whose members
+		// are the events
+		ss.EventStream = hasTrait(traits, "smithy.api#streaming")
+	case "document":
+		ss.Document = true
+	}
+}
+
+// convertMember returns a shapeRefSpec describing a member of the supplied
+// parent shape, recording the member's documentation in the docSpec
+func (c *smithyConverter) convertMember(
+	parentName string,
+	memberName string,
+	member *smithyMember,
+) (*shapeRefSpec, error) {
+	targetName, err := c.convertShape(member.Target)
+	if err != nil {
+		return nil, err
+	}
+	ref := &shapeRefSpec{ShapeName: &targetName}
+	traits := member.Traits
+	var location string
+	switch {
+	case hasTrait(traits, "smithy.api#httpLabel"):
+		location = "uri"
+	case hasTrait(traits, "smithy.api#httpQuery"),
+		hasTrait(traits, "smithy.api#httpQueryParams"):
+		location = "querystring"
+	case hasTrait(traits, "smithy.api#httpHeader"):
+		location = "header"
+	case hasTrait(traits, "smithy.api#httpPrefixHeaders"):
+		location = "headers"
+	case hasTrait(traits, "smithy.api#httpResponseCode"):
+		location = "statusCode"
+	}
+	if location != "" {
+		ref.Location = &location
+	}
+	c.convertMemberTraits(member, ref)
+	var doc string
+	if traitString(traits, "smithy.api#documentation", &doc) {
+		c.shapeDoc(targetName).Refs[parentName+"$"+memberName] = doc
+	}
+	return ref, nil
+}
+
+// convertMemberTraits copies the Smithy traits of a member, and of the
+// member's target shape, that have an equivalent in CORAL models to the
+// shapeRefSpec
+func (c *smithyConverter) convertMemberTraits(
+	member *smithyMember,
+	ref *shapeRefSpec,
+) {
+	traits := member.Traits
+	// The wire name of HTTP-bound members is the value of the binding trait
+	for _, trait := range []string{
+		"smithy.api#httpHeader",
+		"smithy.api#httpQuery",
+		"smithy.api#httpPrefixHeaders",
+		"smithy.api#xmlName",
+		"smithy.api#jsonName",
+	} {
+		if traitString(traits, trait, &ref.LocationName) {
+			break
+		}
+	}
+	traitString(traits, "aws.protocols#ec2QueryName", &ref.QueryName)
+	var ns xmlNamespaceSpec
+	if traitValue(traits, "smithy.api#xmlNamespace", &ns) {
+		ref.XMLNamespace = &ns
+	}
+	ref.XMLAttribute = hasTrait(traits, "smithy.api#xmlAttribute")
+	ref.Flattened = hasTrait(traits, "smithy.api#xmlFlattened")
+	ref.Deprecated, ref.DeprecatedMessage = deprecation(traits)
+	ref.IdempotencyToken = hasTrait(traits, "smithy.api#idempotencyToken")
+	ref.HostLabel = hasTrait(traits, "smithy.api#hostLabel")
+	ref.Box = hasTrait(traits, "smithy.api#box")
+	traitString(traits, "smithy.api#timestampFormat", &ref.TimestampFormat)
+	if target, found := c.model.Shapes[member.Target]; found {
+		ref.Streaming = hasTrait(target.Traits, "smithy.api#streaming")
+		var mediaType string
+		ref.JSONValue = traitString(target.Traits, "smithy.api#mediaType", &mediaType) &&
+			mediaType == "application/json"
+	}
+}
+
+// deprecation returns whether the smithy.api#deprecated trait is present and
+// its message, if any
+func deprecation(traits map[string]json.RawMessage) (bool, string) {
+	var deprecated struct {
+		Message string `json:"message"`
+	}
+	if !traitValue(traits, "smithy.api#deprecated", &deprecated) {
+		return false, ""
+	}
+	return true, deprecated.Message
+}
+
+// convertConstraints copies Smithy constraint traits to the shapeSpec
+func (c *smithyConverter) convertConstraints(
+	traits map[string]json.RawMessage,
+	ss *shapeSpec,
+) {
+	var bounds struct {
+		Min *float64 `json:"min"`
+		Max *float64 `json:"max"`
+	}
+	if traitValue(traits, "smithy.api#length", &bounds) ||
+		traitValue(traits, "smithy.api#range", &bounds) {
+		ss.Min = bounds.Min
+		ss.Max = bounds.Max
+	}
+	var pattern string
+	if traitString(traits, "smithy.api#pattern", &pattern) {
+		ss.Pattern = &pattern
+	}
+	// Smithy 1.0 enums are string shapes with an enum trait
+	var enum []struct {
+		Value string `json:"value"`
+	}
+	if traitValue(traits, "smithy.api#enum", &enum) {
+		for _, e := range enum {
+			ss.Enum = append(ss.Enum, e.Value)
+		}
+	}
+}
+
+// convertError fills in the exception information for shapes with the
+// smithy.api#error trait
+func (c *smithyConverter) convertError(
+	name string,
+	traits map[string]json.RawMessage,
+	ss *shapeSpec,
+) {
+	var fault string
+	if !traitString(traits, "smithy.api#error", &fault) {
+		return
+	}
+	ss.Exception = true
+	errSpec := &errShapeSpec{
+		Code:        name,
+		SenderFault: fault == "client",
+	}
+	var status int
+	if traitValue(traits, "smithy.api#httpError", &status) {
+		errSpec.HTTPStatusCode = &status
+	}
+	var queryError struct {
+		Code             string `json:"code"`
+		HTTPResponseCode *int   `json:"httpResponseCode"`
+	}
+	if traitValue(traits, "aws.protocols#awsQueryError", &queryError) {
+		if queryError.Code != "" {
+			errSpec.Code = queryError.Code
+		}
+		if queryError.HTTPResponseCode != nil {
+			errSpec.HTTPStatusCode = queryError.HTTPResponseCode
+		}
+	}
+	ss.Error = errSpec
+}
+
+// shapeDoc returns the shapeDocSpec for a shape name, creating it if needed
+func (c *smithyConverter) shapeDoc(shapeName string) *shapeDocSpec {
+	doc, found := c.docs.Shapes[shapeName]
+	if !found {
+		doc = &shapeDocSpec{Refs: map[string]string{}}
+		c.docs.Shapes[shapeName] = doc
+	}
+	return doc
+}
+
+func hasTrait(traits map[string]json.RawMessage, trait string) bool {
+	_, found := traits[trait]
+	return found
+}
+
+// traitValue decodes the value of a trait into the supplied pointer,
+// returning false if the trait is absent or cannot be decoded
+func traitValue(
+	traits map[string]json.RawMessage,
+	trait string,
+	into interface{},
+) bool {
+	raw, found := traits[trait]
+	if !found {
+		return false
+	}
+	return json.Unmarshal(raw, into) == nil
+}
+
+// traitString decodes the value of a string-valued trait
+func traitString(
+	traits map[string]json.RawMessage,
+	trait string,
+	into *string,
+) bool {
+	return traitValue(traits, trait, into)
+}
+
+// namespaceOf returns the namespace of a Smithy shape ID, e.g. "smithy.api"
+// for "smithy.api#String"
+func namespaceOf(id string) string {
+	if idx := strings.Index(id, "#"); idx >= 0 {
+		return id[:idx]
+	}
+	return ""
+}
+
+// shapeNameOf returns the shape name of a Smithy shape ID, e.g. "String" for
+// "smithy.api#String"
+func shapeNameOf(id string) string {
+	return id[strings.Index(id, "#")+1:]
+}
@@ -0,0 +1,89 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"testing"
+)
+
+const smithyExampleModel = `{
+  "smithy": "1.0",
+  "shapes": {
+    "com.amazonaws.example#Example": {
+      "type": "service",
+      "version": "2020-01-01",
+      "operations": [{"target": "com.amazonaws.example#GetThing"}],
+      "traits": {
+        "aws.api#service": {"sdkId": "Example", "endpointPrefix": "example"},
+        "aws.protocols#awsJson1_1": {}
+      }
+    },
+    "com.amazonaws.example#GetThing": {
+      "type": "operation",
+      "input": {"target": "com.amazonaws.example#GetThingInput"},
+      "output": {"target": "com.amazonaws.example#Thing"}
+    },
+    "com.amazonaws.example#GetThingInput": {
+      "type": "structure",
+      "members": {
+        "Id": {"target": "smithy.api#String"}
+      }
+    },
+    "com.amazonaws.example#Thing": {
+      "type": "structure",
+      "members": {
+        "Common": {"target": "com.amazonaws.common#Thing"},
+        "Name": {"target": "com.amazonaws.example#String"}
+      }
+    },
+    "com.amazonaws.common#Thing": {
+      "type": "structure",
+      "members": {
+        "Id": {"target": "smithy.api#String"}
+      }
+    },
+    "com.amazonaws.example#String": {
+      "type": "string"
+    }
+  }
+}`
+
+func TestParseSmithyShapeNames(t *testing.T) {
+	api, _, err := parseSmithy([]byte(smithyExampleModel))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		shapeName string
+		member    string
+		target    string
+	}{
+		// The shape in the service's namespace keeps its name...
+		{"Thing", "Common", "ComAmazonawsCommonThing"},
+		// ...and a prelude shape colliding with a model shape is prefixed
+		// with "Smithy"
+		{"Thing", "Name", "String"},
+		{"ComAmazonawsCommonThing", "Id", "SmithyString"},
+		{"GetThingInput", "Id", "SmithyString"},
+	}
+	for _, test := range tests {
+		shape, found := api.Shapes[test.shapeName]
+		if !found {
+			t.Fatalf("expected shape %s", test.shapeName)
+		}
+		ref, found := shape.Members[test.member]
+		if !found || ref.ShapeName == nil {
+			t.Fatalf("expected member %s of shape %s", test.member, test.shapeName)
+		}
+		if *ref.ShapeName != test.target {
+			t.Errorf("%s.%s: expected shape %s, got %s", test.shapeName, test.member, test.target, *ref.ShapeName)
+		}
+	}
+	if op := api.Operations["GetThing"]; op == nil || op.Output == nil || *op.Output.ShapeName != "Thing" {
+		t.Errorf("expected GetThing to return Thing")
+	}
+}
@@ -0,0 +1,1099 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// Specification versions the OpenAPI schema of an API may be converted to.
+// The schema is generated as OpenAPI 3.0 and converted to the other versions.
+const (
+	// SpecVersion20 is Swagger 2.0
+	SpecVersion20 = "2.0"
+	// SpecVersion30 is OpenAPI 3.0
+	SpecVersion30 = "3.0"
+	// SpecVersion31 is OpenAPI 3.1, whose schemas are JSON Schema 2020-12
+	// schemas
+	SpecVersion31 = "3.1"
+)
+
+// SpecVersions are the supported specification versions
+var SpecVersions = []string{SpecVersion20, SpecVersion30, SpecVersion31}
+
+// specVersionNames are the human-readable names of the specification
+// versions
+var specVersionNames = map[string]string{
+	SpecVersion20: "Swagger 2.0",
+	SpecVersion30: "OpenAPI 3.0",
+	SpecVersion31: "OpenAPI 3.1",
+}
+
+// Component reference prefixes in OpenAPI 3 and Swagger 2.0 documents
+var v2RefPrefixes = map[string]string{
+	"#/components/schemas/":         "#/definitions/",
+	"#/components/responses/":       "#/responses/",
+	"#/components/parameters/":      "#/parameters/",
+	"#/components/securitySchemes/": "#/securityDefinitions/",
+}
+
+// Media types of form-encoded bodies, which Swagger 2.0 describes with
+// formData parameters instead of a schema
+var formMediaTypes = []string{mediaTypeForm, "multipart/form-data"}
+
+// ConversionIssue describes a construct of an OpenAPI 3.0 schema that cannot
+// be represented in the specification version the schema is converted to
+type ConversionIssue struct {
+	// Path is the JSON pointer of the construct in the OpenAPI 3.0 schema
+	Path string
+	// Construct names the construct, e.g. "oneOf"
+	Construct string
+	// Resolution says what the conversion did with the construct, e.g.
+	// "moved to x-oneOf"
+	Resolution string
+}
+
+// SpecVersionName returns the human-readable name, e.g. "Swagger 2.0", of a
+// specification version
+func SpecVersionName(specVersion string) string {
+	if name, found := specVersionNames[specVersion]; found {
+		return name
+	}
+	return specVersion
+}
+
+// ConvertSchema returns the supplied OpenAPI 3.0 schema converted to a
+// document of the supplied specification version, as a generic JSON value,
+// along with the constructs of the schema that cannot be represented in that
+// version
+func ConvertSchema(
+	swagger *oai.Swagger,
+	specVersion string,
+) (map[string]interface{}, []*ConversionIssue, error) {
+	b, err := swagger.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc map[string]interface{}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, nil, err
+	}
+	c := &specConverter{doc: doc}
+	switch specVersion {
+	case SpecVersion30:
+		return doc, nil, nil
+	case SpecVersion31:
+		return c.toV31(), c.issues, nil
+	case SpecVersion20:
+		return c.toV2(), c.issues, nil
+	}
+	return nil, nil, fmt.Errorf("unknown spec version %q", specVersion)
+}
+
+// specConverter converts an OpenAPI 3.0 document, collecting the constructs
+// that cannot be represented in the target specification version
+type specConverter struct {
+	doc    map[string]interface{}
+	issues []*ConversionIssue
+}
+
+// report records a construct that cannot be represented
+func (c *specConverter) report(path []string, construct string, resolution string) {
+	c.issues = append(c.issues, &ConversionIssue{
+		Path:       jsonPointer(path),
+		Construct:  construct,
+		Resolution: resolution,
+	})
+}
+
+// jsonPointer returns the JSON pointer for the supplied path segments
+func jsonPointer(path []string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	var b strings.Builder
+	b.WriteString("#")
+	for _, seg := range path {
+		b.WriteString("/")
+		b.WriteString(escaper.Replace(seg))
+	}
+	return b.String()
+}
+
+// subPath returns a copy of path with the supplied segments appended
+func subPath(path []string, segs ...string) []string {
+	res := make([]string, 0, len(path)+len(segs))
+	res = append(res, path...)
+	return append(res, segs...)
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isExtension(key string) bool {
+	return strings.HasPrefix(key, "x-")
+}
+
+// schemaKeywords are the keywords of a schema object whose values contain
+// further schema objects
+var schemaKeywords = map[string]bool{
+	"properties":           true,
+	"items":                true,
+	"additionalProperties": true,
+	"not":                  true,
+	"allOf":                true,
+	"oneOf":                true,
+	"anyOf":                true,
+}
+
+// walkSchemas calls fn with each schema object, and its path, found in the
+// supplied node of an OpenAPI 3.0 document. isSchema is true if the node
+// itself is a schema object, or a list or map of schema objects.
+func walkSchemas(
+	node interface{},
+	path []string,
+	isSchema bool,
+	fn func(schema map[string]interface{}, path []string),
+) {
+	switch n := node.(type) {
+	case []interface{}:
+		for x, item := range n {
+			walkSchemas(item, subPath(path, strconv.Itoa(x)), isSchema, fn)
+		}
+	case map[string]interface{}:
+		if isSchema {
+			fn(n, path)
+			for _, key := range sortedKeys(n) {
+				if !schemaKeywords[key] {
+					continue
+				}
+				if key == "properties" {
+					for _, name := range sortedKeys(asMap(n[key])) {
+						walkSchemas(asMap(n[key])[name], subPath(path, key, name), true, fn)
+					}
+					continue
+				}
+				walkSchemas(n[key], subPath(path, key), true, fn)
+			}
+			return
+		}
+		for _, key := range sortedKeys(n) {
+			switch {
+			case key == "example" || key == "examples" || isExtension(key):
+				continue
+			case key == "schema":
+				walkSchemas(n[key], subPath(path, key), true, fn)
+			case key == "schemas":
+				for _, name := range sortedKeys(asMap(n[key])) {
+					walkSchemas(asMap(n[key])[name], subPath(path, key, name), true, fn)
+				}
+			default:
+				walkSchemas(n[key], subPath(path, key), false, fn)
+			}
+		}
+	}
+}
+
+// toV31 converts the document to OpenAPI 3.1, whose schemas are JSON Schema
+// 2020-12 schemas: nullable becomes a "null" type, boolean exclusiveMinimum
+// and exclusiveMaximum become numbers, single-valued enums become const and
+// example becomes examples. An exclusive bound without the limit it applies
+// to has no equivalent and is reported.
+func (c *specConverter) toV31() map[string]interface{} {
+	c.doc["openapi"] = "3.1.0"
+	walkSchemas(c.doc, nil, false, func(schema map[string]interface{}, path []string) {
+		nullable, _ := schema["nullable"].(bool)
+		delete(schema, "nullable")
+		if typ, found := schema["type"].(string); found && nullable {
+			schema["type"] = []interface{}{typ, "null"}
+			if enum, found := schema["enum"].([]interface{}); found {
+				schema["enum"] = append(enum, nil)
+			}
+			nullable = false
+		}
+		for _, bound := range []string{"Minimum", "Maximum"} {
+			exclusive := "exclusive" + bound
+			limit := strings.ToLower(bound[:1]) + bound[1:]
+			// Schemas visited again after being wrapped below already
+			// have a numeric bound
+			excl, isBool := schema[exclusive].(bool)
+			if !isBool {
+				continue
+			}
+			delete(schema, exclusive)
+			if !excl {
+				continue
+			}
+			if _, found := schema[limit]; !found {
+				c.report(subPath(path, exclusive), exclusive+" without "+limit, "removed")
+				continue
+			}
+			schema[exclusive] = schema[limit]
+			delete(schema, limit)
+		}
+		if enum, _ := schema["enum"].([]interface{}); len(enum) == 1 {
+			schema["const"] = enum[0]
+			delete(schema, "enum")
+		}
+		if example, found := schema["example"]; found {
+			schema["examples"] = []interface{}{example}
+			delete(schema, "example")
+		}
+		if nullable {
+			nullableToAnyOf(schema)
+		}
+	})
+	return c.doc
+}
+
+// annotationKeywords are the keywords of a schema object that annotate the
+// schema rather than constrain its values
+var annotationKeywords = map[string]bool{
+	"title":        true,
+	"description":  true,
+	"default":      true,
+	"examples":     true,
+	"readOnly":     true,
+	"writeOnly":    true,
+	"deprecated":   true,
+	"externalDocs": true,
+	"xml":          true,
+}
+
+// nullableToAnyOf makes a nullable schema without a type, e.g. a $ref or the
+// allOf wrapping a $ref to annotate it, also accept null by moving its
+// constraints into an anyOf with a "null" type schema. The annotations stay
+// on the schema itself.
+func nullableToAnyOf(schema map[string]interface{}) {
+	constraints := map[string]interface{}{}
+	for key, val := range schema {
+		if annotationKeywords[key] || isExtension(key) {
+			continue
+		}
+		constraints[key] = val
+		delete(schema, key)
+	}
+	schema["anyOf"] = []interface{}{
+		constraints,
+		map[string]interface{}{"type": "null"},
+	}
+}
+
+// toV2 converts the document to Swagger 2.0
+func (c *specConverter) toV2() map[string]interface{} {
+	res := map[string]interface{}{"swagger": "2.0"}
+	for _, key := range sortedKeys(c.doc) {
+		val := c.doc[key]
+		path := []string{key}
+		switch key {
+		case "openapi":
+		case "info", "tags", "externalDocs", "security":
+			res[key] = val
+		case "servers":
+			c.serversToV2(res, val, path)
+		case "components":
+			c.componentsToV2(res, asMap(val), path)
+		case "paths":
+			paths := map[string]interface{}{}
+			for _, p := range sortedKeys(asMap(val)) {
+				paths[p] = c.pathItemToV2(asMap(asMap(val)[p]), subPath(path, p))
+			}
+			res[key] = paths
+		default:
+			if isExtension(key) {
+				res[key] = val
+				continue
+			}
+			c.report(path, key, "dropped")
+		}
+	}
+	rewriteV2Refs(res)
+	return res
+}
+
+// rewriteV2Refs replaces the OpenAPI 3 component references in the supplied
+// node of a Swagger 2.0 document with references to the equivalent Swagger
+// 2.0 objects
+func rewriteV2Refs(node interface{}) {
+	switch n := node.(type) {
+	case []interface{}:
+		for _, item := range n {
+			rewriteV2Refs(item)
+		}
+	case map[string]interface{}:
+		for key, val := range n {
+			if ref, ok := val.(string); ok && key == "$ref" {
+				for from, to := range v2RefPrefixes {
+					if strings.HasPrefix(ref, from) {
+						n[key] = to + strings.TrimPrefix(ref, from)
+					}
+				}
+				continue
+			}
+			if key == "example" || key == "examples" || key == "x-examples" {
+				continue
+			}
+			rewriteV2Refs(val)
+		}
+	}
+}
+
+// serversToV2 sets the host, basePath and schemes of the Swagger 2.0
+// document from the first server. Server variables are replaced with their
+// default values and the other servers are kept in an x-servers extension.
+func (c *specConverter) serversToV2(
+	res map[string]interface{},
+	servers interface{},
+	path []string,
+) {
+	list, _ := servers.([]interface{})
+	if len(list) == 0 {
+		return
+	}
+	server := asMap(list[0])
+	rawURL, _ := server["url"].(string)
+	vars := asMap(server["variables"])
+	for _, name := range sortedKeys(vars) {
+		dflt := fmt.Sprintf("%v", asMap(vars[name])["default"])
+		rawURL = strings.Replace(rawURL, "{"+name+"}", dflt, -1)
+		c.report(
+			subPath(path, "0", "variables", name), "server variable",
+			fmt.Sprintf("replaced with its default value %q", dflt),
+		)
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		if u.Host != "" {
+			res["host"] = u.Host
+		}
+		if u.Path != "" {
+			res["basePath"] = u.Path
+		}
+		if u.Scheme != "" {
+			res["schemes"] = []interface{}{u.Scheme}
+		}
+	}
+	if len(list) > 1 {
+		res["x-servers"] = list[1:]
+		for x := 1; x < len(list); x++ {
+			c.report(subPath(path, strconv.Itoa(x)), "additional server", "moved to x-servers")
+		}
+	}
+}
+
+// componentsToV2 converts the components of the document to Swagger 2.0
+// definitions, responses, parameters and securityDefinitions
+func (c *specConverter) componentsToV2(
+	res map[string]interface{},
+	comps map[string]interface{},
+	path []string,
+) {
+	for _, key := range sortedKeys(comps) {
+		val := asMap(comps[key])
+		keyPath := subPath(path, key)
+		converted := map[string]interface{}{}
+		for _, name := range sortedKeys(val) {
+			namePath := subPath(keyPath, name)
+			item := asMap(val[name])
+			switch key {
+			case "schemas":
+				converted[name] = c.schemaToV2(item, namePath)
+			case "responses":
+				resp, _ := c.responseToV2(item, namePath, name)
+				converted[name] = resp
+			case "parameters":
+				if param := c.parameterToV2(item, namePath); param != nil {
+					converted[name] = param
+				}
+			case "securitySchemes":
+				if scheme := c.securitySchemeToV2(item, namePath); scheme != nil {
+					converted[name] = scheme
+				}
+			default:
+				c.report(namePath, "components."+key, "dropped")
+			}
+		}
+		if len(converted) == 0 {
+			continue
+		}
+		switch key {
+		case "schemas":
+			res["definitions"] = converted
+		case "responses", "parameters":
+			res[key] = converted
+		case "securitySchemes":
+			res["securityDefinitions"] = converted
+		}
+	}
+}
+
+// securitySchemeToV2 returns the Swagger 2.0 security scheme for an OpenAPI 3
+// security scheme, or nil if the scheme cannot be represented
+func (c *specConverter) securitySchemeToV2(
+	scheme map[string]interface{},
+	path []string,
+) map[string]interface{} {
+	res := map[string]interface{}{}
+	for _, key := range sortedKeys(scheme) {
+		if isExtension(key) || key == "description" {
+			res[key] = scheme[key]
+		}
+	}
+	switch scheme["type"] {
+	case "apiKey":
+		res["type"] = "apiKey"
+		res["name"] = scheme["name"]
+		res["in"] = scheme["in"]
+	case "http":
+		if scheme["scheme"] == "basic" {
+			res["type"] = "basic"
+			break
+		}
+		res["type"] = "apiKey"
+		res["name"] = "Authorization"
+		res["in"] = "header"
+		c.report(
+			subPath(path, "scheme"), fmt.Sprintf("HTTP %v authentication", scheme["scheme"]),
+			"described as an API key in the Authorization header",
+		)
+	case "oauth2":
+		res["type"] = "oauth2"
+		flows := asMap(scheme["flows"])
+		v2Flows := map[string]string{
+			"implicit":          "implicit",
+			"password":          "password",
+			"clientCredentials": "application",
+			"authorizationCode": "accessCode",
+		}
+		for _, name := range []string{"implicit", "password", "clientCredentials", "authorizationCode"} {
+			flow := asMap(flows[name])
+			if flow == nil {
+				continue
+			}
+			if _, found := res["flow"]; found {
+				c.report(subPath(path, "flows", name), "additional OAuth2 flow", "dropped")
+				continue
+			}
+			res["flow"] = v2Flows[name]
+			for _, key := range []string{"authorizationUrl", "tokenUrl", "scopes"} {
+				if val, found := flow[key]; found {
+					res[key] = val
+				}
+			}
+		}
+	default:
+		c.report(path, fmt.Sprintf("%v security scheme", scheme["type"]), "dropped")
+		return nil
+	}
+	return res
+}
+
+// pathItemToV2 converts an OpenAPI 3 path item to Swagger 2.0
+func (c *specConverter) pathItemToV2(
+	item map[string]interface{},
+	path []string,
+) map[string]interface{} {
+	res := map[string]interface{}{}
+	for _, key := range sortedKeys(item) {
+		val := item[key]
+		keyPath := subPath(path, key)
+		switch key {
+		case "get", "put", "post", "delete", "options", "head", "patch":
+			res[key] = c.operationToV2(asMap(val), keyPath)
+		case "parameters":
+			params := []interface{}{}
+			for x, param := range val.([]interface{}) {
+				if p := c.parameterToV2(asMap(param), subPath(keyPath, strconv.Itoa(x))); p != nil {
+					params = append(params, p)
+				}
+			}
+			res[key] = params
+		case "$ref":
+			res[key] = val
+		default:
+			if isExtension(key) {
+				res[key] = val
+				continue
+			}
+			c.report(keyPath, "path item "+key, "dropped")
+		}
+	}
+	return res
+}
+
+// operationToV2 converts an OpenAPI 3 operation to Swagger 2.0
+func (c *specConverter) operationToV2(
+	op map[string]interface{},
+	path []string,
+) map[string]interface{} {
+	res := map[string]interface{}{}
+	params := []interface{}{}
+	for _, key := range sortedKeys(op) {
+		val := op[key]
+		keyPath := subPath(path, key)
+		switch key {
+		case "operationId", "summary", "description", "tags", "externalDocs", "deprecated", "security":
+			res[key] = val
+		case "parameters":
+			for x, param := range val.([]interface{}) {
+				if p := c.parameterToV2(asMap(param), subPath(keyPath, strconv.Itoa(x))); p != nil {
+					params = append(params, p)
+				}
+			}
+		case "requestBody":
+		case "responses":
+			responses := map[string]interface{}{}
+			produces := map[string]bool{}
+			for _, code := range sortedKeys(asMap(val)) {
+				resp, mediaTypes := c.responseToV2(asMap(asMap(val)[code]), subPath(keyPath, code), code)
+				responses[code] = resp
+				for _, mediaType := range mediaTypes {
+					produces[mediaType] = true
+				}
+			}
+			res[key] = responses
+			if len(produces) > 0 {
+				res["produces"] = sortedSet(produces)
+			}
+		default:
+			if isExtension(key) {
+				res[key] = val
+				continue
+			}
+			c.report(keyPath, key, "dropped")
+		}
+	}
+	if body := asMap(op["requestBody"]); body != nil {
+		bodyParams, consumes, examples := c.requestBodyToV2(body, subPath(path, "requestBody"), params)
+		params = append(params, bodyParams...)
+		if len(consumes) > 0 {
+			res["consumes"] = consumes
+		}
+		if examples != nil {
+			res["x-examples"] = examples
+		}
+	}
+	if len(params) > 0 {
+		res["parameters"] = params
+	}
+	return res
+}
+
+func sortedSet(set map[string]bool) []interface{} {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	res := make([]interface{}, len(keys))
+	for x, key := range keys {
+		res[x] = key
+	}
+	return res
+}
+
+// resolve returns the schema, parameter or response object a $ref refers
+// to, following references to other references, or the supplied object if
+// it is not a reference
+func (c *specConverter) resolve(obj map[string]interface{}) map[string]interface{} {
+	for x := 0; x < 16 && obj != nil; x++ {
+		ref, found := obj["$ref"].(string)
+		if !found || !strings.HasPrefix(ref, "#/") {
+			return obj
+		}
+		var node interface{} = c.doc
+		for _, seg := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			seg = strings.NewReplacer("~1", "/", "~0", "~").Replace(seg)
+			node = asMap(node)[seg]
+		}
+		obj = asMap(node)
+	}
+	return obj
+}
+
+// resolveSchema returns the schema a schema reference refers to, looking
+// through the single-schema allOf wrappers used to annotate references,
+// along with the x- extensions of the wrappers and the resolved schema. The
+// outermost extension wins.
+func (c *specConverter) resolveSchema(
+	schema map[string]interface{},
+) (map[string]interface{}, map[string]interface{}) {
+	exts := map[string]interface{}{}
+	for x := 0; x < 16; x++ {
+		schema = c.resolve(schema)
+		if schema == nil {
+			return nil, exts
+		}
+		for key, val := range schema {
+			if _, found := exts[key]; !found && isExtension(key) {
+				exts[key] = val
+			}
+		}
+		all, _ := schema["allOf"].([]interface{})
+		if _, typed := schema["type"]; typed || len(all) != 1 {
+			return schema, exts
+		}
+		schema = asMap(all[0])
+	}
+	return schema, exts
+}
+
+// primitiveKeywords are the schema keywords that Swagger 2.0 allows on
+// parameters, headers and their items
+var primitiveKeywords = []string{
+	"type", "format", "enum", "default", "minimum", "maximum",
+	"exclusiveMinimum", "exclusiveMaximum", "minLength", "maxLength",
+	"pattern", "minItems", "maxItems", "uniqueItems", "multipleOf",
+}
+
+// primitiveToV2 copies the keywords of the supplied schema to a Swagger 2.0
+// parameter, header or items object, which cannot refer to or contain other
+// schemas. Arrays are serialized in the supplied collection format. False is
+// returned if the schema is not a primitive or an array of primitives.
+func (c *specConverter) primitiveToV2(
+	dst map[string]interface{},
+	schema map[string]interface{},
+	collectionFormat string,
+) bool {
+	schema, _ = c.resolveSchema(schema)
+	if schema == nil {
+		return false
+	}
+	typ, _ := schema["type"].(string)
+	if typ == "" || typ == "object" {
+		return false
+	}
+	for _, key := range primitiveKeywords {
+		if val, found := schema[key]; found {
+			dst[key] = val
+		}
+	}
+	if typ == "array" {
+		items := map[string]interface{}{}
+		if !c.primitiveToV2(items, asMap(schema["items"]), "csv") {
+			return false
+		}
+		dst["items"] = items
+		dst["collectionFormat"] = collectionFormat
+	}
+	return true
+}
+
+// parameterToV2 converts an OpenAPI 3 parameter to Swagger 2.0, or returns
+// nil if the parameter cannot be represented
+func (c *specConverter) parameterToV2(
+	param map[string]interface{},
+	path []string,
+) map[string]interface{} {
+	if ref, found := param["$ref"]; found {
+		return map[string]interface{}{"$ref": ref}
+	}
+	if param["in"] == "cookie" {
+		c.report(path, "cookie parameter", "dropped")
+		return nil
+	}
+	res := map[string]interface{}{}
+	for _, key := range sortedKeys(param) {
+		val := param[key]
+		keyPath := subPath(path, key)
+		switch key {
+		case "name", "in", "description", "required", "allowEmptyValue":
+			res[key] = val
+		case "style", "explode", "schema":
+		case "deprecated":
+			res["x-deprecated"] = val
+			c.report(keyPath, "deprecated parameter", "moved to x-deprecated")
+		case "example", "examples":
+			res["x-"+key] = val
+			c.report(keyPath, "parameter "+key, "moved to x-"+key)
+		default:
+			if isExtension(key) {
+				res[key] = val
+				continue
+			}
+			c.report(keyPath, "parameter "+key, "dropped")
+		}
+	}
+	if schema := asMap(param["schema"]); schema != nil {
+		if !c.primitiveToV2(res, schema, parameterCollectionFormat(param)) {
+			res["type"] = "string"
+			c.report(subPath(path, "schema"), "non-primitive parameter schema", "described as a string")
+		}
+	}
+	return res
+}
+
+// parameterCollectionFormat returns the Swagger 2.0 collection format of an
+// array parameter with the OpenAPI 3 parameter's style
+func parameterCollectionFormat(param map[string]interface{}) string {
+	style, _ := param["style"].(string)
+	explode, hasExplode := param["explode"].(bool)
+	if style == "" && param["in"] == "query" {
+		style = "form"
+	}
+	switch style {
+	case "form":
+		if !hasExplode || explode {
+			return "multi"
+		}
+	case "spaceDelimited":
+		return "ssv"
+	case "pipeDelimited":
+		return "pipes"
+	}
+	return "csv"
+}
+
+// contentSchemaToV2 returns the schema and media types of OpenAPI 3 content.
+// Swagger 2.0 bodies have a single schema for all media types, so the schema
+// of the first media type is used.
+func (c *specConverter) contentSchemaToV2(
+	content map[string]interface{},
+	path []string,
+) (map[string]interface{}, []string) {
+	mediaTypes := sortedKeys(content)
+	var schema map[string]interface{}
+	var schemaJSON []byte
+	for _, mediaType := range mediaTypes {
+		mt := asMap(content[mediaType])
+		mtPath := subPath(path, mediaType)
+		if encoding, found := mt["encoding"]; found && encoding != nil {
+			c.report(subPath(mtPath, "encoding"), "encoding", "dropped")
+		}
+		mtSchema := asMap(mt["schema"])
+		if mtSchema == nil {
+			continue
+		}
+		b, _ := json.Marshal(mtSchema)
+		if schema == nil {
+			schema, schemaJSON = mtSchema, b
+		} else if string(b) != string(schemaJSON) {
+			c.report(subPath(mtPath, "schema"), "media type specific schema", "dropped")
+		}
+	}
+	if schema == nil {
+		return nil, mediaTypes
+	}
+	return c.schemaToV2(schema, subPath(path, mediaTypes[0], "schema")), mediaTypes
+}
+
+// mediaTypeExample is an example of a media type of OpenAPI 3 content
+type mediaTypeExample struct {
+	mediaType string
+	// name is the name of the example, or the media type for the single
+	// example of a media type
+	name  string
+	value interface{}
+}
+
+// mediaTypeExamples returns the examples of the media types of OpenAPI 3
+// content
+func mediaTypeExamples(content map[string]interface{}) []mediaTypeExample {
+	res := []mediaTypeExample{}
+	for _, mediaType := range sortedKeys(content) {
+		mt := asMap(content[mediaType])
+		if example, found := mt["example"]; found {
+			res = append(res, mediaTypeExample{mediaType, mediaType, example})
+		}
+		named := asMap(mt["examples"])
+		for _, name := range sortedKeys(named) {
+			res = append(res, mediaTypeExample{mediaType, name, asMap(named[name])["value"]})
+		}
+	}
+	return res
+}
+
+// examplesExtension returns the value of the x-examples extension keeping
+// the supplied examples, keyed by name
+func examplesExtension(examples []mediaTypeExample) map[string]interface{} {
+	res := map[string]interface{}{}
+	for _, example := range examples {
+		res[example.name] = example.value
+	}
+	return res
+}
+
+// requestBodyToV2 returns the Swagger 2.0 parameters describing an OpenAPI 3
+// request body and the media types the operation consumes. Form-encoded
+// bodies are described by a formData parameter for each of the body schema's
+// properties, and their examples returned for the operation's x-examples
+// extension, and other bodies by a single body parameter.
+func (c *specConverter) requestBodyToV2(
+	body map[string]interface{},
+	path []string,
+	params []interface{},
+) ([]interface{}, []interface{}, map[string]interface{}) {
+	if _, found := body["$ref"]; found {
+		c.report(path, "request body reference", "dropped")
+		return nil, nil, nil
+	}
+	content := asMap(body["content"])
+	consumes := []interface{}{}
+	for _, mediaType := range sortedKeys(content) {
+		consumes = append(consumes, mediaType)
+		if inStrings(mediaType, formMediaTypes) {
+			formParams := c.formBodyToV2(asMap(content[mediaType]), subPath(path, "content", mediaType))
+			var examples map[string]interface{}
+			if mtExamples := mediaTypeExamples(content); len(mtExamples) > 0 {
+				examples = examplesExtension(mtExamples)
+				c.report(subPath(path, "content"), "request body examples", "moved to x-examples of the operation")
+			}
+			return formParams, consumes, examples
+		}
+	}
+	names := map[string]bool{}
+	for _, param := range params {
+		if name, ok := asMap(param)["name"].(string); ok {
+			names[name] = true
+		}
+	}
+	param := map[string]interface{}{
+		"in":   "body",
+		"name": "body",
+	}
+	if names["body"] {
+		param["name"] = "requestBody"
+	}
+	for _, key := range sortedKeys(body) {
+		switch {
+		case key == "description" || key == "required" || isExtension(key):
+			param[key] = body[key]
+		}
+	}
+	schema, _ := c.contentSchemaToV2(content, subPath(path, "content"))
+	if schema == nil {
+		schema = map[string]interface{}{}
+	}
+	param["schema"] = schema
+	if examples := mediaTypeExamples(content); len(examples) > 0 {
+		param["x-examples"] = examplesExtension(examples)
+		c.report(subPath(path, "content"), "request body examples", "moved to x-examples")
+	}
+	return []interface{}{param}, consumes, nil
+}
+
+// formBodyToV2 returns the formData parameters describing the properties of
+// a form-encoded body's schema, including properties of the schemas it
+// combines with allOf
+func (c *specConverter) formBodyToV2(
+	mt map[string]interface{},
+	path []string,
+) []interface{} {
+	props := map[string]interface{}{}
+	required := map[string]bool{}
+	var collect func(schema map[string]interface{})
+	collect = func(schema map[string]interface{}) {
+		schema = c.resolve(schema)
+		for name, prop := range asMap(schema["properties"]) {
+			props[name] = prop
+		}
+		if req, ok := schema["required"].([]interface{}); ok {
+			for _, name := range req {
+				required[fmt.Sprintf("%v", name)] = true
+			}
+		}
+		if all, ok := schema["allOf"].([]interface{}); ok {
+			for _, sub := range all {
+				collect(asMap(sub))
+			}
+		}
+	}
+	collect(asMap(mt["schema"]))
+	params := []interface{}{}
+	for _, name := range sortedKeys(props) {
+		propPath := subPath(path, "schema", "properties", name)
+		param := map[string]interface{}{
+			"in":   "formData",
+			"name": name,
+		}
+		if required[name] {
+			param["required"] = true
+		}
+		prop, exts := c.resolveSchema(asMap(props[name]))
+		if queryName, found := exts["x-aws-query-name"]; found {
+			param["name"] = queryName
+		}
+		if !c.primitiveToV2(param, prop, "multi") {
+			param["type"] = "string"
+			c.report(propPath, "non-primitive form field", "described as a string")
+		} else if serialization, found := exts["x-aws-query-serialization"]; found {
+			// Query protocol lists are numbered form fields, e.g.
+			// Name.member.1, not repeated fields
+			param["x-aws-query-serialization"] = serialization
+			c.report(propPath, "numbered form field list", "described as a multi collection")
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// responseToV2 converts an OpenAPI 3 response with the supplied status code
+// (or component name) to Swagger 2.0, returning the response and the media
+// types of its content
+func (c *specConverter) responseToV2(
+	resp map[string]interface{},
+	path []string,
+	code string,
+) (map[string]interface{}, []string) {
+	if ref, found := resp["$ref"]; found {
+		_, mediaTypes := c.contentSchemaToV2(asMap(c.resolve(resp)["content"]), nil)
+		return map[string]interface{}{"$ref": ref}, mediaTypes
+	}
+	res := map[string]interface{}{}
+	description, _ := resp["description"].(string)
+	if description == "" {
+		description = "Default response"
+		if statusCode, err := strconv.Atoi(code); err == nil && http.StatusText(statusCode) != "" {
+			description = http.StatusText(statusCode)
+		}
+	}
+	res["description"] = description
+	var mediaTypes []string
+	for _, key := range sortedKeys(resp) {
+		val := resp[key]
+		keyPath := subPath(path, key)
+		switch key {
+		case "description":
+		case "content":
+			content := asMap(val)
+			var schema map[string]interface{}
+			schema, mediaTypes = c.contentSchemaToV2(content, keyPath)
+			if schema != nil {
+				res["schema"] = schema
+			}
+			examples := mediaTypeExamples(content)
+			if len(examples) == 0 {
+				continue
+			}
+			// Swagger 2.0 response examples are keyed by media type, so
+			// only the first example of each media type is kept there
+			v2Examples := map[string]interface{}{}
+			for _, example := range examples {
+				if _, found := v2Examples[example.mediaType]; !found {
+					v2Examples[example.mediaType] = example.value
+				}
+			}
+			res["examples"] = v2Examples
+			if len(examples) > len(v2Examples) {
+				res["x-examples"] = examplesExtension(examples)
+				c.report(keyPath, "additional response examples", "moved to x-examples")
+			}
+		case "headers":
+			headers := map[string]interface{}{}
+			for _, name := range sortedKeys(asMap(val)) {
+				headers[name] = c.headerToV2(asMap(asMap(val)[name]), subPath(keyPath, name))
+			}
+			res[key] = headers
+		default:
+			if isExtension(key) {
+				res[key] = val
+				continue
+			}
+			c.report(keyPath, "response "+key, "dropped")
+		}
+	}
+	return res, mediaTypes
+}
+
+// headerToV2 converts an OpenAPI 3 response header to Swagger 2.0
+func (c *specConverter) headerToV2(
+	header map[string]interface{},
+	path []string,
+) map[string]interface{} {
+	header = c.resolve(header)
+	res := map[string]interface{}{}
+	for _, key := range sortedKeys(header) {
+		val := header[key]
+		switch {
+		case key == "description" || isExtension(key):
+			res[key] = val
+		case key == "deprecated":
+			res["x-deprecated"] = val
+			c.report(subPath(path, key), "deprecated header", "moved to x-deprecated")
+		}
+	}
+	if !c.primitiveToV2(res, asMap(header["schema"]), "csv") {
+		res["type"] = "string"
+		c.report(subPath(path, "schema"), "non-primitive header schema", "described as a string")
+	}
+	return res
+}
+
+// schemaToV2 converts an OpenAPI 3 schema to a Swagger 2.0 schema. oneOf,
+// anyOf and not, and the nullable, writeOnly and deprecated keywords, have
+// no Swagger 2.0 equivalent and are kept as x- extensions.
+func (c *specConverter) schemaToV2(
+	schema map[string]interface{},
+	path []string,
+) map[string]interface{} {
+	if ref, found := schema["$ref"]; found {
+		return map[string]interface{}{"$ref": ref}
+	}
+	res := map[string]interface{}{}
+	for _, key := range sortedKeys(schema) {
+		val := schema[key]
+		keyPath := subPath(path, key)
+		switch key {
+		case "properties":
+			props := map[string]interface{}{}
+			for _, name := range sortedKeys(asMap(val)) {
+				props[name] = c.schemaToV2(asMap(asMap(val)[name]), subPath(keyPath, name))
+			}
+			res[key] = props
+		case "items":
+			res[key] = c.schemaToV2(asMap(val), keyPath)
+		case "additionalProperties":
+			if sub := asMap(val); sub != nil {
+				res[key] = c.schemaToV2(sub, keyPath)
+			} else {
+				res[key] = val
+			}
+		case "allOf", "oneOf", "anyOf":
+			subs := []interface{}{}
+			for x, sub := range val.([]interface{}) {
+				subs = append(subs, c.schemaToV2(asMap(sub), subPath(keyPath, strconv.Itoa(x))))
+			}
+			if key == "allOf" {
+				res[key] = subs
+				continue
+			}
+			res["x-"+key] = subs
+			c.report(keyPath, key, "moved to x-"+key)
+		case "not":
+			res["x-not"] = c.schemaToV2(asMap(val), keyPath)
+			c.report(keyPath, key, "moved to x-not")
+		case "nullable", "writeOnly", "deprecated":
+			res["x-"+key] = val
+			c.report(keyPath, key, "moved to x-"+key)
+		case "discriminator":
+			discriminator := asMap(val)
+			res[key] = discriminator["propertyName"]
+			if _, found := discriminator["mapping"]; found {
+				c.report(subPath(keyPath, "mapping"), "discriminator mapping", "dropped")
+			}
+		default:
+			res[key] = val
+		}
+	}
+	return res
+}
@@ -0,0 +1,349 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// convertInput is an OpenAPI 3.0 schema with a form-encoded and a JSON
+// request body, references, oneOf, nullable and exclusive bound schemas and
+// a server with variables
+const convertInput = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "2020-01-01"},
+  "servers": [
+    {
+      "url": "https://test.{region}.amazonaws.com",
+      "variables": {"region": {"default": "us-east-1"}}
+    },
+    {"url": "https://test.amazonaws.com"}
+  ],
+  "paths": {
+    "/form": {
+      "post": {
+        "operationId": "CreateThing",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/x-www-form-urlencoded": {
+              "schema": {"$ref": "#/components/schemas/CreateThingInput"}
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Success",
+            "content": {
+              "text/xml": {"schema": {"$ref": "#/components/schemas/Thing"}}
+            }
+          }
+        }
+      }
+    },
+    "/json": {
+      "put": {
+        "operationId": "PutThing",
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {"$ref": "#/components/schemas/Thing"}
+            }
+          }
+        },
+        "responses": {"200": {"description": "Success"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Circle": {
+        "type": "object",
+        "properties": {"Radius": {"type": "number"}}
+      },
+      "CreateThingInput": {
+        "type": "object",
+        "required": ["Name"],
+        "properties": {
+          "Name": {"type": "string"},
+          "Size": {"type": "integer", "minimum": 0, "exclusiveMinimum": true}
+        }
+      },
+      "Square": {
+        "type": "object",
+        "properties": {"Side": {"type": "number"}}
+      },
+      "Thing": {
+        "type": "object",
+        "properties": {
+          "Name": {"type": "string", "nullable": true, "enum": ["a"]},
+          "Parent": {
+            "description": "The parent",
+            "nullable": true,
+            "allOf": [{"$ref": "#/components/schemas/Thing"}]
+          },
+          "Shape": {
+            "oneOf": [
+              {"$ref": "#/components/schemas/Circle"},
+              {"$ref": "#/components/schemas/Square"}
+            ]
+          },
+          "Weight": {"type": "number", "exclusiveMaximum": true}
+        }
+      }
+    }
+  }
+}`
+
+const convertWantV2 = `{
+  "swagger": "2.0",
+  "info": {"title": "Test", "version": "2020-01-01"},
+  "host": "test.us-east-1.amazonaws.com",
+  "schemes": ["https"],
+  "x-servers": [{"url": "https://test.amazonaws.com"}],
+  "paths": {
+    "/form": {
+      "post": {
+        "operationId": "CreateThing",
+        "consumes": ["application/x-www-form-urlencoded"],
+        "produces": ["text/xml"],
+        "parameters": [
+          {"in": "formData", "name": "Name", "required": true, "type": "string"},
+          {
+            "in": "formData",
+            "name": "Size",
+            "type": "integer",
+            "minimum": 0,
+            "exclusiveMinimum": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {"$ref": "#/definitions/Thing"}
+          }
+        }
+      }
+    },
+    "/json": {
+      "put": {
+        "operationId": "PutThing",
+        "consumes": ["application/json"],
+        "parameters": [
+          {
+            "in": "body",
+            "name": "body",
+            "schema": {"$ref": "#/definitions/Thing"}
+          }
+        ],
+        "responses": {"200": {"description": "Success"}}
+      }
+    }
+  },
+  "definitions": {
+    "Circle": {
+      "type": "object",
+      "properties": {"Radius": {"type": "number"}}
+    },
+    "CreateThingInput": {
+      "type": "object",
+      "required": ["Name"],
+      "properties": {
+        "Name": {"type": "string"},
+        "Size": {"type": "integer", "minimum": 0, "exclusiveMinimum": true}
+      }
+    },
+    "Square": {
+      "type": "object",
+      "properties": {"Side": {"type": "number"}}
+    },
+    "Thing": {
+      "type": "object",
+      "properties": {
+        "Name": {"type": "string", "x-nullable": true, "enum": ["a"]},
+        "Parent": {
+          "description": "The parent",
+          "x-nullable": true,
+          "allOf": [{"$ref": "#/definitions/Thing"}]
+        },
+        "Shape": {
+          "x-oneOf": [
+            {"$ref": "#/definitions/Circle"},
+            {"$ref": "#/definitions/Square"}
+          ]
+        },
+        "Weight": {"type": "number", "exclusiveMaximum": true}
+      }
+    }
+  }
+}`
+
+const convertWantV31 = `{
+  "openapi": "3.1.0",
+  "info": {"title": "Test", "version": "2020-01-01"},
+  "servers": [
+    {
+      "url": "https://test.{region}.amazonaws.com",
+      "variables": {"region": {"default": "us-east-1"}}
+    },
+    {"url": "https://test.amazonaws.com"}
+  ],
+  "paths": {
+    "/form": {
+      "post": {
+        "operationId": "CreateThing",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/x-www-form-urlencoded": {
+              "schema": {"$ref": "#/components/schemas/CreateThingInput"}
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Success",
+            "content": {
+              "text/xml": {"schema": {"$ref": "#/components/schemas/Thing"}}
+            }
+          }
+        }
+      }
+    },
+    "/json": {
+      "put": {
+        "operationId": "PutThing",
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {"$ref": "#/components/schemas/Thing"}
+            }
+          }
+        },
+        "responses": {"200": {"description": "Success"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Circle": {
+        "type": "object",
+        "properties": {"Radius": {"type": "number"}}
+      },
+      "CreateThingInput": {
+        "type": "object",
+        "required": ["Name"],
+        "properties": {
+          "Name": {"type": "string"},
+          "Size": {"type": "integer", "exclusiveMinimum": 0}
+        }
+      },
+      "Square": {
+        "type": "object",
+        "properties": {"Side": {"type": "number"}}
+      },
+      "Thing": {
+        "type": "object",
+        "properties": {
+          "Name": {"type": ["string", "null"], "enum": ["a", null]},
+          "Parent": {
+            "description": "The parent",
+            "anyOf": [
+              {"allOf": [{"$ref": "#/components/schemas/Thing"}]},
+              {"type": "null"}
+            ]
+          },
+          "Shape": {
+            "oneOf": [
+              {"$ref": "#/components/schemas/Circle"},
+              {"$ref": "#/components/schemas/Square"}
+            ]
+          },
+          "Weight": {"type": "number"}
+        }
+      }
+    }
+  }
+}`
+
+func TestConvertSchema(t *testing.T) {
+	tests := []struct {
+		specVersion string
+		want        string
+		wantIssues  []ConversionIssue
+	}{
+		{
+			specVersion: SpecVersion20,
+			want:        convertWantV2,
+			wantIssues: []ConversionIssue{
+				{"#/components/schemas/Thing/properties/Name/nullable", "nullable", "moved to x-nullable"},
+				{"#/components/schemas/Thing/properties/Parent/nullable", "nullable", "moved to x-nullable"},
+				{"#/components/schemas/Thing/properties/Shape/oneOf", "oneOf", "moved to x-oneOf"},
+				{"#/servers/0/variables/region", "server variable", `replaced with its default value "us-east-1"`},
+				{"#/servers/1", "additional server", "moved to x-servers"},
+			},
+		},
+		{
+			specVersion: SpecVersion30,
+			want:        convertInput,
+		},
+		{
+			specVersion: SpecVersion31,
+			want:        convertWantV31,
+			wantIssues: []ConversionIssue{
+				{"#/components/schemas/Thing/properties/Weight/exclusiveMaximum", "exclusiveMaximum without maximum", "removed"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.specVersion, func(t *testing.T) {
+			swagger := &oai.Swagger{}
+			if err := json.Unmarshal([]byte(convertInput), swagger); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			doc, issues, err := ConvertSchema(swagger, test.specVersion)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Compare the JSON values, as the converted document mixes
+			// typed and generic values
+			var got, want interface{}
+			b, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = json.Unmarshal([]byte(test.want), &want); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				t.Errorf("got document:\n%s\nwant:\n%s", gotJSON, test.want)
+			}
+			if len(issues) != len(test.wantIssues) {
+				t.Fatalf("got %d issues, want %d: %+v", len(issues), len(test.wantIssues), issues)
+			}
+			for x, issue := range issues {
+				if *issue != test.wantIssues[x] {
+					t.Errorf("issue %d: got %+v, want %+v", x, *issue, test.wantIssues[x])
+				}
+			}
+		})
+	}
+}
+
+func TestConvertSchemaUnknownVersion(t *testing.T) {
+	if _, _, err := ConvertSchema(&oai.Swagger{}, "4.0"); err == nil {
+		t.Fatalf("expected an error for spec version 4.0")
+	}
+}
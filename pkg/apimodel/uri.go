@@ -0,0 +1,140 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+// uriLabelRegex matches a label in a requestUri template, e.g. "{Bucket}" or
+// the greedy "{Key+}"
+var uriLabelRegex = regexp.MustCompile(`\{([^}+]+)(\+?)\}`)
+
+// requestURI is a parsed requestUri template from an operation's HTTP
+// binding. CORAL models use the requestUri for more than OpenAPI paths can
+// express: greedy labels like "{Key+}" match more than one path segment and
+// static query strings like "?acl" select between operations on the same
+// path.
+type requestURI struct {
+	// Path is the path part of the requestUri with greedy label markers
+	// removed, e.g. "/{Bucket}/{Key}" for "/{Bucket}/{Key+}?uploads"
+	Path string
+	// Greedy contains the names of the path's greedy labels
+	Greedy []string
+	// Query maps the names of the static query string parameters to their
+	// values, e.g. {"uploads": ""} for "/{Bucket}/{Key+}?uploads"
+	Query map[string]string
+}
+
+// parseRequestURI returns the requestURI described by the supplied requestUri
+// template
+func parseRequestURI(uri string) *requestURI {
+	res := &requestURI{Query: map[string]string{}}
+	path := uri
+	if idx := strings.Index(uri, "?"); idx >= 0 {
+		path = uri[:idx]
+		for _, part := range strings.Split(uri[idx+1:], "&") {
+			if part == "" {
+				continue
+			}
+			kv := strings.SplitN(part, "=", 2)
+			value := ""
+			if len(kv) == 2 {
+				value = kv[1]
+			}
+			res.Query[kv[0]] = value
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+	for _, match := range uriLabelRegex.FindAllStringSubmatch(path, -1) {
+		if match[2] != "" {
+			res.Greedy = append(res.Greedy, match[1])
+		}
+	}
+	res.Path = uriLabelRegex.ReplaceAllString(path, "{$1}")
+	return res
+}
+
+// templateKey returns the path with every label replaced by "{}". OpenAPI
+// considers two paths that differ only in their label names to be the same
+// path.
+func (u *requestURI) templateKey() string {
+	return uriLabelRegex.ReplaceAllString(u.Path, "{}")
+}
+
+// applyTo adds a constant query parameter to the operation for each of the
+// requestUri's static query string parameters and flags the operation's path
+// parameters for greedy labels with the x-aws-greedy extension
+func (u *requestURI) applyTo(op *oai.Operation) {
+	for _, paramRef := range op.Parameters {
+		param := paramRef.Value
+		if param.In == oai.ParameterInPath && inStrings(param.Name, u.Greedy) {
+			addExtension(&param.ExtensionProps, "x-aws-greedy", true)
+		}
+	}
+	names := make([]string, 0, len(u.Query))
+	for name := range u.Query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if op.Parameters.GetByInAndName(oai.ParameterInQuery, name) != nil {
+			continue
+		}
+		value := u.Query[name]
+		param := oai.NewQueryParameter(name).WithRequired(true)
+		param.Schema = oai.NewSchemaRef("", oai.NewStringSchema().WithEnum(value))
+		param.AllowEmptyValue = value == ""
+		op.Parameters = append(op.Parameters, &oai.ParameterRef{Value: param})
+	}
+}
+
+// operationPaths returns a map, keyed by operation name, of the OpenAPI path
+// for each of the supplied operations. Operations whose paths collide, either
+// because they share a path and HTTP method or because their paths differ
+// only in label names, have a fragment containing the operation name appended
+// to their path to keep each OpenAPI path unique.
+//
+// See https://github.com/OAI/OpenAPI-Specification/issues/1635#issuecomment-607444697
+func operationPaths(
+	ops map[string]*opSpec,
+	uris map[string]*requestURI,
+) map[string]string {
+	byTemplate := map[string][]string{}
+	for opName := range ops {
+		key := uris[opName].templateKey()
+		byTemplate[key] = append(byTemplate[key], opName)
+	}
+	res := make(map[string]string, len(ops))
+	for _, opNames := range byTemplate {
+		paths := map[string]bool{}
+		methods := map[string]bool{}
+		collision := false
+		for _, opName := range opNames {
+			paths[uris[opName].Path] = true
+			method := ops[opName].HTTP.Method
+			if methods[method] {
+				collision = true
+			}
+			methods[method] = true
+		}
+		collision = collision || len(paths) > 1
+		for _, opName := range opNames {
+			res[opName] = uris[opName].Path
+			if collision {
+				res[opName] += "#" + opName
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,206 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"strings"
+	"testing"
+
+	oai "github.com/getkin/kin-openapi/openapi3"
+)
+
+const s3Model = `{
+  "metadata": {
+    "apiVersion": "2006-03-01",
+    "endpointPrefix": "s3",
+    "protocol": "rest-xml",
+    "serviceFullName": "Amazon Simple Storage Service",
+    "serviceId": "S3",
+    "signatureVersion": "s3"
+  },
+  "operations": {
+    "CreateMultipartUpload": {
+      "name": "CreateMultipartUpload",
+      "http": {"method": "POST", "requestUri": "/{Bucket}/{Key+}?uploads"},
+      "input": {"shape": "CreateMultipartUploadRequest"},
+      "output": {"shape": "CreateMultipartUploadOutput"}
+    },
+    "GetObject": {
+      "name": "GetObject",
+      "http": {"method": "GET", "requestUri": "/{Bucket}/{Key+}"},
+      "input": {"shape": "GetObjectRequest"},
+      "output": {"shape": "GetObjectOutput"},
+      "errors": [{"shape": "NoSuchKey"}]
+    },
+    "GetObjectAcl": {
+      "name": "GetObjectAcl",
+      "http": {"method": "GET", "requestUri": "/{Bucket}/{Key+}?acl"},
+      "input": {"shape": "GetObjectRequest"},
+      "output": {"shape": "GetObjectAclOutput"}
+    },
+    "DeleteBucket": {
+      "name": "DeleteBucket",
+      "http": {"method": "DELETE", "requestUri": "/{Bucket}", "responseCode": 204},
+      "input": {"shape": "DeleteBucketRequest"},
+      "errors": [{"shape": "NoSuchBucket"}, {"shape": "BucketNotEmpty"}]
+    }
+  },
+  "shapes": {
+    "CreateMultipartUploadRequest": {
+      "type": "structure",
+      "required": ["Bucket", "Key"],
+      "members": {
+        "Bucket": {"shape": "BucketName", "location": "uri", "locationName": "Bucket"},
+        "Key": {"shape": "ObjectKey", "location": "uri", "locationName": "Key"},
+        "ContentType": {"shape": "String", "location": "header", "locationName": "Content-Type"}
+      }
+    },
+    "CreateMultipartUploadOutput": {
+      "type": "structure",
+      "members": {"UploadId": {"shape": "String"}}
+    },
+    "GetObjectRequest": {
+      "type": "structure",
+      "required": ["Bucket", "Key"],
+      "members": {
+        "Bucket": {"shape": "BucketName", "location": "uri", "locationName": "Bucket"},
+        "Key": {"shape": "ObjectKey", "location": "uri", "locationName": "Key"},
+        "VersionId": {"shape": "String", "location": "querystring", "locationName": "versionId"}
+      }
+    },
+    "GetObjectOutput": {
+      "type": "structure",
+      "members": {"ETag": {"shape": "String", "location": "header", "locationName": "ETag"}}
+    },
+    "GetObjectAclOutput": {
+      "type": "structure",
+      "members": {"Owner": {"shape": "String"}}
+    },
+    "DeleteBucketRequest": {
+      "type": "structure",
+      "required": ["Bucket"],
+      "members": {
+        "Bucket": {"shape": "BucketName", "location": "uri", "locationName": "Bucket"}
+      }
+    },
+    "NoSuchBucket": {"type": "structure", "members": {}, "exception": true},
+    "NoSuchKey": {"type": "structure", "members": {}, "exception": true},
+    "BucketNotEmpty": {
+      "type": "structure",
+      "members": {},
+      "error": {"httpStatusCode": 409, "senderFault": true},
+      "exception": true
+    },
+    "BucketName": {"type": "string"},
+    "ObjectKey": {"type": "string", "min": 1},
+    "String": {"type": "string"}
+  }
+}`
+
+func TestParseRequestURI(t *testing.T) {
+	tests := []struct {
+		uri    string
+		path   string
+		greedy []string
+		query  map[string]string
+	}{
+		{"/", "/", nil, map[string]string{}},
+		{"", "/", nil, map[string]string{}},
+		{"/{Bucket}", "/{Bucket}", nil, map[string]string{}},
+		{"/{Bucket}/{Key+}", "/{Bucket}/{Key}", []string{"Key"}, map[string]string{}},
+		{"/{Bucket}/{Key+}?uploads", "/{Bucket}/{Key}", []string{"Key"}, map[string]string{"uploads": ""}},
+		{"/{Bucket}?list-type=2", "/{Bucket}", nil, map[string]string{"list-type": "2"}},
+		{"/{Bucket}?versions&encoding-type=url", "/{Bucket}", nil, map[string]string{"versions": "", "encoding-type": "url"}},
+	}
+	for _, test := range tests {
+		got := parseRequestURI(test.uri)
+		if got.Path != test.path {
+			t.Errorf("%q: expected path %s, got %s", test.uri, test.path, got.Path)
+		}
+		if strings.Join(got.Greedy, ",") != strings.Join(test.greedy, ",") {
+			t.Errorf("%q: expected greedy labels %v, got %v", test.uri, test.greedy, got.Greedy)
+		}
+		if len(got.Query) != len(test.query) {
+			t.Errorf("%q: expected query %v, got %v", test.uri, test.query, got.Query)
+			continue
+		}
+		for name, value := range test.query {
+			if gotValue, found := got.Query[name]; !found || gotValue != value {
+				t.Errorf("%q: expected query %v, got %v", test.uri, test.query, got.Query)
+				break
+			}
+		}
+	}
+}
+
+// findParameter returns the operation's parameter with the supplied location
+// and name, failing the test if there is no such parameter
+func findParameter(t *testing.T, op *oai.Operation, in string, name string) *oai.Parameter {
+	t.Helper()
+	param := op.Parameters.GetByInAndName(in, name)
+	if param == nil {
+		t.Fatalf("%s: no %s parameter %s", op.OperationID, in, name)
+	}
+	return param
+}
+
+func TestOperationPaths(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "s3", s3Model))
+	tests := []struct {
+		operationID string
+		path        string
+	}{
+		// GetObject and GetObjectAcl share a path and method, so every
+		// operation on the path gets a fragment to keep the paths unique
+		{"CreateMultipartUpload", "/{Bucket}/{Key}#CreateMultipartUpload"},
+		{"GetObject", "/{Bucket}/{Key}#GetObject"},
+		{"GetObjectAcl", "/{Bucket}/{Key}#GetObjectAcl"},
+		{"DeleteBucket", "/{Bucket}"},
+	}
+	for _, test := range tests {
+		path, _ := findOperation(t, swagger, test.operationID)
+		if path != test.path {
+			t.Errorf("%s: expected path %s, got %s", test.operationID, test.path, path)
+		}
+	}
+}
+
+func TestRequestURIParameters(t *testing.T) {
+	swagger := testSchema(t, newTestAPI(t, "s3", s3Model))
+	_, op := findOperation(t, swagger, "CreateMultipartUpload")
+
+	bucket := findParameter(t, op, oai.ParameterInPath, "Bucket")
+	if !bucket.Required {
+		t.Errorf("expected path parameter Bucket to be required")
+	}
+	if _, found := bucket.Extensions["x-aws-greedy"]; found {
+		t.Errorf("expected path parameter Bucket not to be greedy")
+	}
+	key := findParameter(t, op, oai.ParameterInPath, "Key")
+	if greedy, _ := key.Extensions["x-aws-greedy"].(bool); !greedy {
+		t.Errorf("expected path parameter Key to be greedy, got %v", key.Extensions["x-aws-greedy"])
+	}
+	findParameter(t, op, oai.ParameterInHeader, "Content-Type")
+
+	// The static query string becomes a required constant query parameter
+	uploads := findParameter(t, op, oai.ParameterInQuery, "uploads")
+	if !uploads.Required || !uploads.AllowEmptyValue {
+		t.Errorf("expected query parameter uploads to be required and allow an empty value")
+	}
+	if enum := uploads.Schema.Value.Enum; len(enum) != 1 || enum[0] != "" {
+		t.Errorf("expected query parameter uploads to only allow the empty string, got %v", enum)
+	}
+	if len(op.Parameters) != 4 {
+		t.Errorf("expected 4 parameters, got %d", len(op.Parameters))
+	}
+
+	// Query string members are optional query parameters
+	_, op = findOperation(t, swagger, "GetObject")
+	if versionID := findParameter(t, op, oai.ParameterInQuery, "versionId"); versionID.Required {
+		t.Errorf("expected query parameter versionId to be optional")
+	}
+}
@@ -0,0 +1,137 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+type waiterAcceptorSpec struct {
+	Matcher  string      `json:"matcher"`
+	Argument string      `json:"argument,omitempty"`
+	Expected interface{} `json:"expected"`
+	State    string      `json:"state"`
+}
+
+type waiterSpec struct {
+	Operation   string                `json:"operation"`
+	Delay       int                   `json:"delay"`
+	MaxAttempts int                   `json:"maxAttempts"`
+	Acceptors   []*waiterAcceptorSpec `json:"acceptors"`
+}
+
+type waitersSpec struct {
+	Waiters map[string]*waiterSpec `json:"waiters"`
+}
+
+// parseWaiters returns the waiter definitions, keyed by waiter name, in the
+// supplied waiters-2.json document
+func parseWaiters(b []byte) (map[string]*waiterSpec, error) {
+	var spec waitersSpec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode waiters: %v", err)
+	}
+	return spec.Waiters, nil
+}
+
+// WaiterAcceptor describes one of the conditions a Waiter checks after each
+// call to its operation
+type WaiterAcceptor struct {
+	// Matcher is the kind of match performed: "path", "pathAll" or "pathAny"
+	// match Argument against the operation's output, "status" matches the
+	// HTTP status code and "error" matches the error code returned by the
+	// operation
+	Matcher string
+	// Argument is the JMESPath expression evaluated against the operation's
+	// output for the path matchers
+	Argument string
+	// Expected is the value that must be matched
+	Expected interface{}
+	// State is the state the waiter transitions to when the acceptor
+	// matches: "success", "failure" or "retry"
+	State string
+}
+
+// Waiter describes how to poll an operation until a resource reaches a
+// desired state
+type Waiter struct {
+	// Name is the name of the waiter, e.g. "InstanceRunning"
+	Name string
+	// Operation is the name of the operation that is polled
+	Operation string
+	// Delay is the number of seconds to wait between attempts
+	Delay int
+	// MaxAttempts is the maximum number of attempts before giving up
+	MaxAttempts int
+	// Acceptors are the conditions checked after each attempt
+	Acceptors []*WaiterAcceptor
+}
+
+func newWaiter(name string, spec *waiterSpec) *Waiter {
+	w := &Waiter{
+		Name:        name,
+		Operation:   spec.Operation,
+		Delay:       spec.Delay,
+		MaxAttempts: spec.MaxAttempts,
+		Acceptors:   make([]*WaiterAcceptor, len(spec.Acceptors)),
+	}
+	for x, acc := range spec.Acceptors {
+		w.Acceptors[x] = &WaiterAcceptor{
+			Matcher:  acc.Matcher,
+			Argument: acc.Argument,
+			Expected: acc.Expected,
+			State:    acc.State,
+		}
+	}
+	return w
+}
+
+// GetWaiters returns the API's waiter definitions sorted by waiter name
+func (a *API) GetWaiters() []*Waiter {
+	res := make([]*Waiter, 0, len(a.apiSpec.Waiters))
+	for name, spec := range a.apiSpec.Waiters {
+		res = append(res, newWaiter(name, spec))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// waitersExtensions returns a map, keyed by operation name, of the values of
+// the x-aws-waiters extension for each operation that is polled by one or
+// more waiters
+func waitersExtensions(waiters map[string]*waiterSpec) map[string]map[string]interface{} {
+	res := map[string]map[string]interface{}{}
+	for name, spec := range waiters {
+		ext, found := res[spec.Operation]
+		if !found {
+			ext = map[string]interface{}{}
+			res[spec.Operation] = ext
+		}
+		acceptors := make([]map[string]interface{}, len(spec.Acceptors))
+		for x, acc := range spec.Acceptors {
+			accExt := map[string]interface{}{
+				"matcher":  acc.Matcher,
+				"expected": acc.Expected,
+				"state":    acc.State,
+			}
+			if acc.Argument != "" {
+				accExt["argument"] = acc.Argument
+			}
+			acceptors[x] = accExt
+		}
+		ext[name] = map[string]interface{}{
+			"delay":       spec.Delay,
+			"maxAttempts": spec.MaxAttempts,
+			"acceptors":   acceptors,
+		}
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package apimodel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jaypipes/aws-api-tools/pkg/model"
+)
+
+const dynamodbWaiters = `{
+  "version": 2,
+  "waiters": {
+    "TableExists": {
+      "delay": 20,
+      "operation": "DescribeTable",
+      "maxAttempts": 25,
+      "acceptors": [
+        {
+          "expected": "ACTIVE",
+          "matcher": "path",
+          "state": "success",
+          "argument": "Table.TableStatus"
+        },
+        {
+          "expected": "ResourceNotFoundException",
+          "matcher": "error",
+          "state": "retry"
+        }
+      ]
+    },
+    "TableNotExists": {
+      "delay": 20,
+      "operation": "DescribeTable",
+      "maxAttempts": 25,
+      "acceptors": [
+        {
+          "expected": "ResourceNotFoundException",
+          "matcher": "error",
+          "state": "success"
+        }
+      ]
+    }
+  }
+}`
+
+func TestGetWaiters(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:   dynamodbTablesModel,
+		model.WaitersFile: dynamodbWaiters,
+	})
+	waiters := api.GetWaiters()
+	if len(waiters) != 2 {
+		t.Fatalf("expected 2 waiters, got %d", len(waiters))
+	}
+	want := &Waiter{
+		Name:        "TableExists",
+		Operation:   "DescribeTable",
+		Delay:       20,
+		MaxAttempts: 25,
+		Acceptors: []*WaiterAcceptor{
+			{Matcher: "path", Argument: "Table.TableStatus", Expected: "ACTIVE", State: "success"},
+			{Matcher: "error", Expected: "ResourceNotFoundException", State: "retry"},
+		},
+	}
+	if !reflect.DeepEqual(waiters[0], want) {
+		t.Errorf("expected waiter %+v, got %+v", want, waiters[0])
+	}
+	if waiters[1].Name != "TableNotExists" {
+		t.Errorf("expected waiters sorted by name, got %s second", waiters[1].Name)
+	}
+	if _, err := parseWaiters([]byte(`{"waiters": {"TableExists": {"delay": "20"}}}`)); err == nil {
+		t.Errorf("expected an error for a string delay")
+	}
+}
+
+func TestWaitersExtension(t *testing.T) {
+	api := newTestAPIFromFiles(t, "dynamodb", map[string]string{
+		model.ModelFile:   dynamodbTablesModel,
+		model.WaitersFile: dynamodbWaiters,
+	})
+	swagger := testSchema(t, api)
+	// Both waiters poll DescribeTable, so both are in its extension
+	_, op := findOperation(t, swagger, "DescribeTable")
+	want := map[string]interface{}{
+		"TableExists": map[string]interface{}{
+			"delay":       20,
+			"maxAttempts": 25,
+			"acceptors": []map[string]interface{}{
+				{"matcher": "path", "argument": "Table.TableStatus", "expected": "ACTIVE", "state": "success"},
+				{"matcher": "error", "expected": "ResourceNotFoundException", "state": "retry"},
+			},
+		},
+		"TableNotExists": map[string]interface{}{
+			"delay":       20,
+			"maxAttempts": 25,
+			"acceptors": []map[string]interface{}{
+				{"matcher": "error", "expected": "ResourceNotFoundException", "state": "success"},
+			},
+		},
+	}
+	if got := op.Extensions["x-aws-waiters"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected x-aws-waiters %v, got %v", want, got)
+	}
+	_, op = findOperation(t, swagger, "ListTables")
+	if ext, found := op.Extensions["x-aws-waiters"]; found {
+		t.Errorf("expected no x-aws-waiters on ListTables, got %v", ext)
+	}
+}
@@ -8,9 +8,13 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
-	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	sdkmodelapi "github.com/aws/aws-sdk-go/private/model/api"
 )
@@ -25,33 +29,101 @@ var (
 	ErrServiceNotFound = errors.New(
 		"no such service",
 	)
+	ErrInvalidModelsDirectory = errors.New(
+		"invalid models directory",
+	)
+	ErrModelFileNotFound = errors.New(
+		"model file not found",
+	)
+	ErrAPIVersionNotFound = errors.New(
+		"no such API version",
+	)
+	ErrLoaderRequiresFileSystem = errors.New(
+		"the aws-sdk-go model loader requires a filesystem model source",
+	)
 )
 
+// apiVersionLayout is the time layout of API version strings
+const apiVersionLayout = "2006-01-02"
+
 // SDKHelper is a helper struct that helps work with the aws-sdk-go models and
 // API model loader
 type SDKHelper struct {
 	basePath string
-	loader   *sdkmodelapi.Loader
+	source   ModelSource
+	// endpoints caches the contents of the endpoints file, which is shared
+	// by every API
+	endpointsOnce sync.Once
+	endpoints     []byte
+	endpointsErr  error
 }
 
-// NewSDKHelper returns a new SDKHelper object
+// NewSDKHelper returns a new SDKHelper object that reads API models from the
+// models/apis/ directory of an aws-sdk-go source repository at basePath
 func NewSDKHelper(basePath string) *SDKHelper {
 	return &SDKHelper{
 		basePath: basePath,
-		loader: &sdkmodelapi.Loader{
-			BaseImport:            basePath,
-			IgnoreUnsupportedAPIs: true,
+		source: &FileSystemSource{
+			apisPath: filepath.Join(basePath, "models", "apis"),
 		},
 	}
 }
 
-// API returns the aws-sdk-go API model for a supplied service alias
+// NewSDKHelperFromModelsDir returns a new SDKHelper object that reads API
+// models from a local directory instead of a clone of the aws-sdk-go
+// repository. The directory may contain either aws-sdk-go CORAL models (see
+// NewFileSystemSource) or aws-sdk-go-v2 Smithy models (see NewSmithySource).
+func NewSDKHelperFromModelsDir(modelsDir string) (*SDKHelper, error) {
+	var src ModelSource
+	src, err := NewFileSystemSource(modelsDir)
+	if errors.Is(err, ErrInvalidModelsDirectory) {
+		var smithyErr error
+		src, smithyErr = NewSmithySource(modelsDir)
+		if smithyErr != nil {
+			return nil, fmt.Errorf(
+				"%v and no Smithy models found: %v", err, smithyErr,
+			)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return &SDKHelper{
+		basePath: modelsDir,
+		source:   src,
+	}, nil
+}
+
+// NewSDKHelperFromSource returns a new SDKHelper object that reads API models
+// from the supplied ModelSource
+func NewSDKHelperFromSource(source ModelSource) *SDKHelper {
+	return &SDKHelper{
+		source: source,
+	}
+}
+
+// Source returns the ModelSource the SDKHelper reads API models from
+func (h *SDKHelper) Source() ModelSource {
+	return h.source
+}
+
+// API returns the aws-sdk-go API model for a supplied service alias. Because
+// the aws-sdk-go model loader can only read model files from disk, this is
+// only supported when the SDKHelper reads from a FileSystemSource.
 func (h *SDKHelper) API(serviceAlias string) (*sdkmodelapi.API, error) {
-	modelPath, _, err := h.ModelAndDocsPath(serviceAlias)
+	src, ok := h.source.(*FileSystemSource)
+	if !ok {
+		return nil, ErrLoaderRequiresFileSystem
+	}
+	apiVersion, err := h.APIVersion(serviceAlias)
 	if err != nil {
 		return nil, err
 	}
-	apis, err := h.loader.Load([]string{modelPath})
+	modelPath := filepath.Join(src.apisPath, serviceAlias, apiVersion, ModelFile)
+	loader := &sdkmodelapi.Loader{
+		BaseImport:            h.basePath,
+		IgnoreUnsupportedAPIs: true,
+	}
+	apis, err := loader.Load([]string{modelPath})
 	if err != nil {
 		return nil, err
 	}
@@ -63,43 +135,142 @@ func (h *SDKHelper) API(serviceAlias string) (*sdkmodelapi.API, error) {
 	return nil, ErrServiceNotFound
 }
 
-// ModelAndDocsPath returns two string paths to the supplied service alias'
-// model and doc JSON files
-func (h *SDKHelper) ModelAndDocsPath(
+// ServiceAliases returns the aliases of all services in the model source
+func (h *SDKHelper) ServiceAliases() ([]string, error) {
+	return h.source.Services()
+}
+
+// ReadFile returns the contents of one of the model files (ModelFile,
+// DocsFile, etc) for the supplied service alias and API version. If
+// apiVersion is empty, the newest API version of the service is used.
+func (h *SDKHelper) ReadFile(
 	serviceAlias string,
-) (string, string, error) {
-	apiVersion, err := h.APIVersion(serviceAlias)
+	apiVersion string,
+	fileName string,
+) ([]byte, error) {
+	apiVersion, err := h.resolveAPIVersion(serviceAlias, apiVersion)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	versionPath := filepath.Join(
-		h.basePath, "models", "apis", serviceAlias, apiVersion,
-	)
-	modelPath := filepath.Join(versionPath, "api-2.json")
-	docsPath := filepath.Join(versionPath, "docs-2.json")
-	return modelPath, docsPath, nil
+	rc, err := h.source.Open(serviceAlias, apiVersion, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
 }
 
-// APIVersion returns the API version (e.g. "2012-10-03") for a service API
+// ModelAndDocs returns the contents of the supplied service alias' model and
+// doc JSON files for an API version. If apiVersion is empty, the newest API
+// version of the service is used. The returned doc JSON is nil if the
+// service has no doc JSON file, as is the case for Smithy models.
+func (h *SDKHelper) ModelAndDocs(
+	serviceAlias string,
+	apiVersion string,
+) ([]byte, []byte, error) {
+	apiVersion, err := h.resolveAPIVersion(serviceAlias, apiVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	model, err := h.ReadFile(serviceAlias, apiVersion, ModelFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs, err := h.ReadFile(serviceAlias, apiVersion, DocsFile)
+	if err != nil && !errors.Is(err, ErrModelFileNotFound) {
+		return nil, nil, err
+	}
+	return model, docs, nil
+}
+
+// Endpoints returns the contents of the endpoints file describing the
+// endpoints of all services in each AWS partition. An error wrapping
+// ErrModelFileNotFound is returned if the ModelSource does not provide an
+// endpoints file.
+func (h *SDKHelper) Endpoints() ([]byte, error) {
+	h.endpointsOnce.Do(func() {
+		src, ok := h.source.(EndpointsSource)
+		if !ok {
+			h.endpointsErr = fmt.Errorf(
+				"%w: model source has no %s", ErrModelFileNotFound, EndpointsFile,
+			)
+			return
+		}
+		rc, err := src.OpenEndpoints()
+		if err != nil {
+			h.endpointsErr = err
+			return
+		}
+		defer rc.Close()
+		h.endpoints, h.endpointsErr = ioutil.ReadAll(rc)
+	})
+	return h.endpoints, h.endpointsErr
+}
+
+// APIVersion returns the newest API version (e.g. "2012-10-03") for a
+// service API, which is the last of the APIVersions
 func (h *SDKHelper) APIVersion(serviceAlias string) (string, error) {
-	apiPath := filepath.Join(h.basePath, "models", "apis", serviceAlias)
-	versionDirs, err := ioutil.ReadDir(apiPath)
+	versions, err := h.APIVersions(serviceAlias)
 	if err != nil {
 		return "", err
 	}
-	for _, f := range versionDirs {
-		version := f.Name()
-		fp := filepath.Join(apiPath, version)
-		fi, err := os.Lstat(fp)
-		if err != nil {
-			return "", err
+	return versions[len(versions)-1], nil
+}
+
+// APIVersions returns all API versions for a service API, sorted from oldest
+// to newest. API versions are dates (e.g. "2012-10-03"). Versions that do not
+// parse as a date, e.g. "2012-11-05-beta" or "latest", are sorted lexically
+// before the dated versions, so that the newest version, which is used by
+// default, is always a dated version if the service has one.
+func (h *SDKHelper) APIVersions(serviceAlias string) ([]string, error) {
+	versions, err := h.source.Versions(serviceAlias)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf(
+			"%w for service %s", ErrNoValidVersionDirectory, serviceAlias,
+		)
+	}
+	res := make([]string, len(versions))
+	copy(res, versions)
+	sort.SliceStable(res, func(i, j int) bool {
+		ti, erri := time.Parse(apiVersionLayout, res[i])
+		tj, errj := time.Parse(apiVersionLayout, res[j])
+		switch {
+		case erri == nil && errj == nil:
+			return ti.Before(tj)
+		case erri == nil:
+			return false
+		case errj == nil:
+			return true
 		}
-		if !fi.IsDir() {
-			return "", ErrInvalidVersionDirectory
+		return res[i] < res[j]
+	})
+	return res, nil
+}
+
+// resolveAPIVersion returns the newest API version for the service if
+// apiVersion is empty, otherwise checks that the service has apiVersion
+func (h *SDKHelper) resolveAPIVersion(
+	serviceAlias string,
+	apiVersion string,
+) (string, error) {
+	versions, err := h.APIVersions(serviceAlias)
+	if err != nil {
+		return "", err
+	}
+	if apiVersion == "" {
+		return versions[len(versions)-1], nil
+	}
+	for _, version := range versions {
+		if version == apiVersion {
+			return version, nil
 		}
-		// TODO(jaypipes): handle more than one version? doesn't seem like
-		// there is ever more than one.
-		return version, nil
 	}
-	return "", ErrNoValidVersionDirectory
+	return "", fmt.Errorf(
+		"%w: service %s has no API version %s (available: %s)",
+		ErrAPIVersionNotFound, serviceAlias, apiVersion,
+		strings.Join(versions, ", "),
+	)
 }
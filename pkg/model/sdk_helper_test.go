@@ -0,0 +1,66 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAPIVersions(t *testing.T) {
+	tests := []struct {
+		versions []string
+		sorted   []string
+		newest   string
+	}{
+		{
+			[]string{"2012-11-05", "2009-02-01"},
+			[]string{"2009-02-01", "2012-11-05"},
+			"2012-11-05",
+		},
+		{
+			// Versions that are not dates are never the newest
+			[]string{"latest", "2012-11-05", "2012-11-05-beta", "2009-02-01"},
+			[]string{"2012-11-05-beta", "latest", "2009-02-01", "2012-11-05"},
+			"2012-11-05",
+		},
+		{
+			[]string{"v2", "v1"},
+			[]string{"v1", "v2"},
+			"v2",
+		},
+	}
+	for _, test := range tests {
+		src := NewMemorySource()
+		for _, version := range test.versions {
+			src.Add("sqs", version, ModelFile, []byte(version))
+		}
+		h := NewSDKHelperFromSource(src)
+		sorted, err := h.APIVersions("sqs")
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.versions, err)
+		}
+		if strings.Join(sorted, ",") != strings.Join(test.sorted, ",") {
+			t.Errorf("%v: expected versions %v, got %v", test.versions, test.sorted, sorted)
+		}
+		newest, err := h.APIVersion("sqs")
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.versions, err)
+		}
+		if newest != test.newest {
+			t.Errorf("%v: expected newest version %s, got %s", test.versions, test.newest, newest)
+		}
+		// The default model is the newest version's
+		model, _, err := h.ModelAndDocs("sqs", "")
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.versions, err)
+		}
+		if string(model) != test.newest {
+			t.Errorf("%v: expected the model of version %s, got %s", test.versions, test.newest, model)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"io"
+)
+
+const (
+	// ModelFile is the name of the file containing an API's CORAL model
+	ModelFile = "api-2.json"
+	// DocsFile is the name of the file containing an API's documentation
+	DocsFile = "docs-2.json"
+	// PaginatorsFile is the name of the file containing an API's paginator
+	// definitions
+	PaginatorsFile = "paginators-1.json"
+	// WaitersFile is the name of the file containing an API's waiter
+	// definitions
+	WaitersFile = "waiters-2.json"
+	// ExamplesFile is the name of the file containing an API's request and
+	// response examples
+	ExamplesFile = "examples-1.json"
+	// EndpointsFile is the name of the file describing the endpoints of all
+	// services in each AWS partition
+	EndpointsFile = "endpoints.json"
+)
+
+// ModelSource provides access to API model files laid out the same way as the
+// models/apis/ directory of the aws-sdk-go repository, i.e. one set of model
+// files per service alias and API version:
+//
+//	<alias>/<version>/api-2.json
+//	<alias>/<version>/docs-2.json
+//	<alias>/<version>/paginators-1.json
+//	...
+type ModelSource interface {
+	// Services returns the aliases of all services in the source
+	Services() ([]string, error)
+	// Versions returns the API versions available for a service alias. An
+	// error wrapping ErrServiceNotFound is returned if the source has no
+	// such service.
+	Versions(serviceAlias string) ([]string, error)
+	// Open returns a reader for one of the model files (ModelFile, DocsFile,
+	// etc) for a service alias and API version. An error wrapping
+	// ErrModelFileNotFound is returned if the file does not exist. Callers
+	// must close the returned reader.
+	Open(serviceAlias string, version string, fileName string) (io.ReadCloser, error)
+}
+
+// EndpointsSource is implemented by ModelSources that also provide the
+// endpoints file found in the models/endpoints/ directory of the aws-sdk-go
+// repository
+type EndpointsSource interface {
+	// OpenEndpoints returns a reader for the EndpointsFile. An error wrapping
+	// ErrModelFileNotFound is returned if the source has no endpoints file.
+	// Callers must close the returned reader.
+	OpenEndpoints() (io.ReadCloser, error)
+}
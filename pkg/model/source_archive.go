@@ -0,0 +1,160 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	ErrUnsupportedArchive = errors.New(
+		"unsupported archive format, expected tar.gz or zip",
+	)
+)
+
+// apisPathMarker is the path segment that precedes the service alias in the
+// names of model files contained in an aws-sdk-go release archive, e.g.
+// aws-sdk-go-1.33.1/models/apis/sns/2010-03-31/api-2.json
+const apisPathMarker = "models/apis/"
+
+// endpointsPath is the path, relative to the root of the aws-sdk-go
+// repository, of the endpoints file
+const endpointsPath = "models/endpoints/" + EndpointsFile
+
+// ArchiveSource is a ModelSource that reads model files from a tar.gz or zip
+// archive of the aws-sdk-go repository, such as a release tarball downloaded
+// from GitHub. All model files under models/apis/ are read into memory when
+// the ArchiveSource is created, along with the endpoints file under
+// models/endpoints/; other archive entries are skipped.
+type ArchiveSource struct {
+	*MemorySource
+}
+
+// NewArchiveSource returns a new ArchiveSource containing the model files
+// found in the tar.gz or zip archive at archivePath. The archive format is
+// determined from the file content, not the file name.
+func NewArchiveSource(archivePath string) (*ArchiveSource, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	src := &ArchiveSource{NewMemorySource()}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		err = src.readTarGz(br)
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		var fi os.FileInfo
+		if fi, err = f.Stat(); err != nil {
+			return nil, err
+		}
+		err = src.readZip(f, fi.Size())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArchive, archivePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %v", archivePath, err)
+	}
+	if len(src.files) == 0 {
+		return nil, fmt.Errorf(
+			"%w: expected to find %s in archive %s",
+			ErrInvalidModelsDirectory, apisPathMarker, archivePath,
+		)
+	}
+	return src, nil
+}
+
+func (s *ArchiveSource) readTarGz(r io.Reader) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err = s.addEntry(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *ArchiveSource) readZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		err = s.addEntry(zf.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addEntry reads the archive entry into memory if its name looks like
+// [<prefix>/]models/apis/<alias>/<version>/<file> or
+// [<prefix>/]models/endpoints/endpoints.json
+func (s *ArchiveSource) addEntry(name string, r io.Reader) error {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if name == endpointsPath || strings.HasSuffix(name, "/"+endpointsPath) {
+		contents, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		s.SetEndpoints(contents)
+		return nil
+	}
+	idx := strings.Index("/"+name, "/"+apisPathMarker)
+	if idx < 0 {
+		return nil
+	}
+	parts := strings.Split(name[idx+len(apisPathMarker):], "/")
+	if len(parts) != 3 {
+		return nil
+	}
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.Add(parts[0], parts[1], parts[2], contents)
+	return nil
+}
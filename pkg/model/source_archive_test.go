@@ -0,0 +1,150 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archiveFiles are the entries of a test aws-sdk-go release archive, keyed
+// by entry name
+var archiveFiles = map[string]string{
+	"aws-sdk-go-1.33.1/README.md":                                    "# AWS SDK for Go",
+	"aws-sdk-go-1.33.1/models/apis/sns/2010-03-31/api-2.json":        `{"metadata": {}}`,
+	"aws-sdk-go-1.33.1/models/apis/sns/2010-03-31/docs-2.json":       `{"service": "SNS"}`,
+	"aws-sdk-go-1.33.1/models/apis/sqs/2012-11-05/api-2.json":        `{"metadata": {}}`,
+	"aws-sdk-go-1.33.1/models/apis/sqs/2012-11-05/smoke/smoke.json":  `{}`,
+	"aws-sdk-go-1.33.1/models/endpoints/endpoints.json":              `{"partitions": []}`,
+	"aws-sdk-go-1.33.1/private/model/api/codegentest/service/api.go": "package api",
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, contents := range files {
+		hdr := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatalf("failed to write tar entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create zip entry: %v", err)
+		}
+		if _, err = w.Write([]byte(contents)); err != nil {
+			t.Fatalf("failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewArchiveSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-archive")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	// The archive file names have no extension, as the format is detected
+	// from the contents
+	tests := []struct {
+		name     string
+		contents []byte
+		err      error
+	}{
+		{"tar.gz", tarGzArchive(t, archiveFiles), nil},
+		{"zip", zipArchive(t, archiveFiles), nil},
+		{"text", []byte("not an archive"), ErrUnsupportedArchive},
+		{"empty", []byte{}, ErrUnsupportedArchive},
+		{"no-models", tarGzArchive(t, map[string]string{"README.md": "# README"}), ErrInvalidModelsDirectory},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archivePath := filepath.Join(dir, test.name)
+			if err := ioutil.WriteFile(archivePath, test.contents, 0644); err != nil {
+				t.Fatalf("failed to write archive: %v", err)
+			}
+			src, err := NewArchiveSource(archivePath)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("expected error %v, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			services, err := src.Services()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(services, ",") != "sns,sqs" {
+				t.Errorf("expected services [sns sqs], got %v", services)
+			}
+			for _, file := range []string{ModelFile, DocsFile} {
+				r, err := src.Open("sns", "2010-03-31", file)
+				if err != nil {
+					t.Fatalf("unexpected error opening %s: %v", file, err)
+				}
+				got, _ := ioutil.ReadAll(r)
+				r.Close()
+				want := archiveFiles["aws-sdk-go-1.33.1/models/apis/sns/2010-03-31/"+file]
+				if string(got) != want {
+					t.Errorf("expected %s contents %q, got %q", file, want, got)
+				}
+			}
+			// Entries nested deeper than <alias>/<version>/<file> are
+			// skipped
+			if _, err := src.Open("sqs", "2012-11-05", "smoke"); !errors.Is(err, ErrModelFileNotFound) {
+				t.Errorf("expected ErrModelFileNotFound for smoke, got %v", err)
+			}
+			r, err := src.OpenEndpoints()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer r.Close()
+			if got, _ := ioutil.ReadAll(r); string(got) != archiveFiles["aws-sdk-go-1.33.1/models/endpoints/endpoints.json"] {
+				t.Errorf("unexpected endpoints contents %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileSystemSource is a ModelSource that reads model files from a directory on
+// the local filesystem
+type FileSystemSource struct {
+	// apisPath is the directory containing one subdirectory per service alias,
+	// e.g. $basePath/models/apis
+	apisPath string
+}
+
+// NewFileSystemSource returns a new FileSystemSource that reads model files
+// from modelsDir. modelsDir may be either the root of an aws-sdk-go source
+// tree or vendored snapshot (containing models/apis/) or the models/
+// directory itself (containing apis/). An error wrapping
+// ErrInvalidModelsDirectory is returned if neither layout is found.
+func NewFileSystemSource(modelsDir string) (*FileSystemSource, error) {
+	fi, err := os.Stat(modelsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(
+				"%w: %s does not exist", ErrInvalidModelsDirectory, modelsDir,
+			)
+		}
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf(
+			"%w: %s is not a directory", ErrInvalidModelsDirectory, modelsDir,
+		)
+	}
+	candidates := []string{
+		filepath.Join(modelsDir, "models", "apis"),
+		filepath.Join(modelsDir, "apis"),
+	}
+	for _, apisPath := range candidates {
+		fi, err := os.Stat(apisPath)
+		if err == nil && fi.IsDir() {
+			return &FileSystemSource{apisPath: apisPath}, nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"%w: expected to find either %s or %s",
+		ErrInvalidModelsDirectory, candidates[0], candidates[1],
+	)
+}
+
+// Services returns the aliases of all services that have a directory in the
+// models/apis/ directory
+func (s *FileSystemSource) Services() ([]string, error) {
+	apiDirs, err := ioutil.ReadDir(s.apisPath)
+	if err != nil {
+		return nil, err
+	}
+	aliases := []string{}
+	for _, f := range apiDirs {
+		fp := filepath.Join(s.apisPath, f.Name())
+		fi, err := os.Stat(fp)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			continue
+		}
+		aliases = append(aliases, f.Name())
+	}
+	return aliases, nil
+}
+
+// Versions returns the names of the version directories for a service alias
+func (s *FileSystemSource) Versions(serviceAlias string) ([]string, error) {
+	apiPath := filepath.Join(s.apisPath, serviceAlias)
+	versionDirs, err := ioutil.ReadDir(apiPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(
+				"%w: expected to find %s", ErrServiceNotFound, apiPath,
+			)
+		}
+		return nil, err
+	}
+	versions := []string{}
+	for _, f := range versionDirs {
+		version := f.Name()
+		fp := filepath.Join(apiPath, version)
+		fi, err := os.Stat(fp)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			return nil, fmt.Errorf(
+				"%w: %s", ErrInvalidVersionDirectory, fp,
+			)
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Open returns the opened model file
+func (s *FileSystemSource) Open(
+	serviceAlias string,
+	version string,
+	fileName string,
+) (io.ReadCloser, error) {
+	fp := filepath.Join(s.apisPath, serviceAlias, version, fileName)
+	f, err := os.Open(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(
+				"%w: expected to find %s", ErrModelFileNotFound, fp,
+			)
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// OpenEndpoints returns the opened endpoints file from the endpoints/
+// directory next to the apis/ directory
+func (s *FileSystemSource) OpenEndpoints() (io.ReadCloser, error) {
+	fp := filepath.Join(filepath.Dir(s.apisPath), "endpoints", EndpointsFile)
+	f, err := os.Open(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(
+				"%w: expected to find %s", ErrModelFileNotFound, fp,
+			)
+		}
+		return nil, err
+	}
+	return f, nil
+}
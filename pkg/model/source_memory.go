@@ -0,0 +1,108 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// MemorySource is a ModelSource that holds model files in memory. It is
+// useful for feeding small, hand-written models to apimodel.New
+type MemorySource struct {
+	// files is a map, keyed by service alias, of maps, keyed by API version,
+	// of maps, keyed by model file name, of model file contents
+	files map[string]map[string]map[string][]byte
+	// endpoints is the contents of the endpoints file, if any
+	endpoints []byte
+}
+
+// NewMemorySource returns a new, empty MemorySource
+func NewMemorySource() *MemorySource {
+	return &MemorySource{
+		files: map[string]map[string]map[string][]byte{},
+	}
+}
+
+// Add adds (or replaces) the contents of a model file for a service alias and
+// API version
+func (s *MemorySource) Add(
+	serviceAlias string,
+	version string,
+	fileName string,
+	contents []byte,
+) {
+	versions, found := s.files[serviceAlias]
+	if !found {
+		versions = map[string]map[string][]byte{}
+		s.files[serviceAlias] = versions
+	}
+	files, found := versions[version]
+	if !found {
+		files = map[string][]byte{}
+		versions[version] = files
+	}
+	files[fileName] = contents
+}
+
+// SetEndpoints sets (or replaces) the contents of the endpoints file
+func (s *MemorySource) SetEndpoints(contents []byte) {
+	s.endpoints = contents
+}
+
+// Services returns the sorted aliases of all services in the source
+func (s *MemorySource) Services() ([]string, error) {
+	aliases := make([]string, 0, len(s.files))
+	for alias := range s.files {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// Versions returns the sorted API versions for a service alias
+func (s *MemorySource) Versions(serviceAlias string) ([]string, error) {
+	versions, found := s.files[serviceAlias]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceAlias)
+	}
+	res := make([]string, 0, len(versions))
+	for version := range versions {
+		res = append(res, version)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// Open returns a reader over the contents of a model file
+func (s *MemorySource) Open(
+	serviceAlias string,
+	version string,
+	fileName string,
+) (io.ReadCloser, error) {
+	contents, found := s.files[serviceAlias][version][fileName]
+	if !found {
+		return nil, fmt.Errorf(
+			"%w: expected to find %s/%s/%s",
+			ErrModelFileNotFound, serviceAlias, version, fileName,
+		)
+	}
+	return ioutil.NopCloser(bytes.NewReader(contents)), nil
+}
+
+// OpenEndpoints returns a reader over the contents of the endpoints file
+func (s *MemorySource) OpenEndpoints() (io.ReadCloser, error) {
+	if s.endpoints == nil {
+		return nil, fmt.Errorf(
+			"%w: expected to find %s", ErrModelFileNotFound, EndpointsFile,
+		)
+	}
+	return ioutil.NopCloser(bytes.NewReader(s.endpoints)), nil
+}
@@ -0,0 +1,203 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SmithySource is a ModelSource that reads Smithy JSON AST models from a
+// directory laid out like the codegen/sdk-codegen/aws-models/ directory of the
+// aws-sdk-go-v2 repository, i.e. one file per service named either
+// <alias>.json or <alias>.<version>.json. Smithy models carry their
+// documentation inline, so the Smithy model is the only model file (served as
+// ModelFile) available for each service.
+type SmithySource struct {
+	sync.Mutex
+	modelsPath string
+	// files is a map, keyed by service alias, of maps, keyed by API version,
+	// of Smithy model file paths. API versions of <alias>.json files are
+	// read from the model's service shape when first needed.
+	files map[string]map[string]string
+}
+
+// NewSmithySource returns a new SmithySource that reads Smithy models from
+// modelsDir. modelsDir may be either the root of an aws-sdk-go-v2 source tree
+// (containing codegen/sdk-codegen/aws-models/) or the aws-models/ directory
+// itself. An error wrapping ErrInvalidModelsDirectory is returned if no
+// Smithy models are found.
+func NewSmithySource(modelsDir string) (*SmithySource, error) {
+	modelsPath := modelsDir
+	nested := filepath.Join(modelsDir, "codegen", "sdk-codegen", "aws-models")
+	if fi, err := os.Stat(nested); err == nil && fi.IsDir() {
+		modelsPath = nested
+	}
+	entries, err := ioutil.ReadDir(modelsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(
+				"%w: %s does not exist", ErrInvalidModelsDirectory, modelsDir,
+			)
+		}
+		return nil, err
+	}
+	src := &SmithySource{
+		modelsPath: modelsPath,
+		files:      map[string]map[string]string{},
+	}
+	for _, f := range entries {
+		name := f.Name()
+		if f.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		alias := strings.TrimSuffix(name, ".json")
+		version := ""
+		if idx := strings.Index(alias, "."); idx >= 0 {
+			version = alias[idx+1:]
+			alias = alias[:idx]
+			if _, err := time.Parse(apiVersionLayout, version); err != nil {
+				continue
+			}
+		}
+		if _, found := src.files[alias]; !found {
+			src.files[alias] = map[string]string{}
+		}
+		src.files[alias][version] = filepath.Join(modelsPath, name)
+	}
+	if len(src.files) == 0 {
+		return nil, fmt.Errorf(
+			"%w: expected to find Smithy JSON models in %s",
+			ErrInvalidModelsDirectory, modelsPath,
+		)
+	}
+	// Make sure this is really a directory of Smithy models and not some
+	// other directory of JSON files. Only the first model is checked, because
+	// checking them all would mean parsing every model before every command;
+	// the other models are checked when they are opened.
+	if err := src.checkFirstModel(); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// checkFirstModel returns an error wrapping ErrInvalidModelsDirectory if the
+// first model file, in alias and version order, is not a Smithy model
+func (s *SmithySource) checkFirstModel() error {
+	files := s.files[s.sortedAliases()[0]]
+	versions := make([]string, 0, len(files))
+	for version := range files {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	if _, err := smithyServiceVersion(files[versions[0]]); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidModelsDirectory, err)
+	}
+	return nil
+}
+
+// Services returns the sorted aliases of all services in the source
+func (s *SmithySource) Services() ([]string, error) {
+	return s.sortedAliases(), nil
+}
+
+// Versions returns the sorted API versions for a service alias. An error
+// wrapping ErrInvalidModelsDirectory is returned if the service's <alias>.json
+// file has the same version as one of its <alias>.<version>.json files,
+// because there is no telling which of the two models is the right one.
+func (s *SmithySource) Versions(serviceAlias string) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	files, found := s.files[serviceAlias]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceAlias)
+	}
+	if fp, unversioned := files[""]; unversioned {
+		version, err := smithyServiceVersion(fp)
+		if err != nil {
+			return nil, err
+		}
+		if other, found := files[version]; found {
+			return nil, fmt.Errorf(
+				"%w: %s and %s are both version %s of service %s",
+				ErrInvalidModelsDirectory, fp, other, version, serviceAlias,
+			)
+		}
+		delete(files, "")
+		files[version] = fp
+	}
+	versions := make([]string, 0, len(files))
+	for version := range files {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// Open returns the opened Smithy model file when fileName is ModelFile. All
+// other model files are reported as not found.
+func (s *SmithySource) Open(
+	serviceAlias string,
+	version string,
+	fileName string,
+) (io.ReadCloser, error) {
+	if _, err := s.Versions(serviceAlias); err != nil {
+		return nil, err
+	}
+	s.Lock()
+	fp, found := s.files[serviceAlias][version]
+	s.Unlock()
+	if !found || fileName != ModelFile {
+		return nil, fmt.Errorf(
+			"%w: no %s for %s version %s in Smithy models directory %s",
+			ErrModelFileNotFound, fileName, serviceAlias, version, s.modelsPath,
+		)
+	}
+	return os.Open(fp)
+}
+
+func (s *SmithySource) sortedAliases() []string {
+	aliases := make([]string, 0, len(s.files))
+	for alias := range s.files {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// smithyServiceVersion returns the version of the service shape in the
+// Smithy model at the supplied path
+func smithyServiceVersion(fp string) (string, error) {
+	b, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return "", err
+	}
+	var model struct {
+		Smithy *string `json:"smithy"`
+		Shapes map[string]struct {
+			Type    string `json:"type"`
+			Version string `json:"version"`
+		} `json:"shapes"`
+	}
+	if err = json.Unmarshal(b, &model); err != nil || model.Smithy == nil {
+		return "", fmt.Errorf("%s is not a Smithy JSON AST model", fp)
+	}
+	for _, shape := range model.Shapes {
+		if shape.Type == "service" {
+			return shape.Version, nil
+		}
+	}
+	return "", fmt.Errorf("expected to find a service shape in %s", fp)
+}
@@ -0,0 +1,107 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// smithyModel returns a minimal Smithy JSON AST model of a service with the
+// supplied version
+func smithyModel(version string) string {
+	return `{
+  "smithy": "1.0",
+  "shapes": {
+    "com.amazonaws.sqs#AmazonSQS": {"type": "service", "version": "` + version + `"}
+  }
+}`
+}
+
+// newSmithyDir returns a temporary directory containing the supplied files,
+// keyed by file name. Callers must remove the directory.
+func newSmithyDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "aws-models")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			os.RemoveAll(dir)
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestSmithySourceVersions(t *testing.T) {
+	tests := []struct {
+		files    map[string]string
+		versions []string
+		err      error
+	}{
+		{
+			map[string]string{"sqs.json": smithyModel("2012-11-05")},
+			[]string{"2012-11-05"},
+			nil,
+		},
+		{
+			map[string]string{
+				"sqs.json":            smithyModel("2012-11-05"),
+				"sqs.2009-02-01.json": smithyModel("2009-02-01"),
+			},
+			[]string{"2009-02-01", "2012-11-05"},
+			nil,
+		},
+		{
+			// Both files are version 2012-11-05 of the service
+			map[string]string{
+				"sqs.json":            smithyModel("2012-11-05"),
+				"sqs.2012-11-05.json": smithyModel("2012-11-05"),
+			},
+			nil,
+			ErrInvalidModelsDirectory,
+		},
+	}
+	for _, test := range tests {
+		dir := newSmithyDir(t, test.files)
+		defer os.RemoveAll(dir)
+		src, err := NewSmithySource(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		versions, err := src.Versions("sqs")
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error %v, got %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(versions, ",") != strings.Join(test.versions, ",") {
+			t.Errorf("expected versions %v, got %v", test.versions, versions)
+		}
+	}
+}
+
+func TestNewSmithySourceChecksFirstModel(t *testing.T) {
+	dir := newSmithyDir(t, map[string]string{
+		"a.json": `{"not": "smithy"}`,
+		"b.json": smithyModel("2012-11-05"),
+	})
+	defer os.RemoveAll(dir)
+	if _, err := NewSmithySource(dir); !errors.Is(err, ErrInvalidModelsDirectory) {
+		t.Errorf("expected error %v, got %v", ErrInvalidModelsDirectory, err)
+	}
+}